@@ -0,0 +1,531 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ValueKind defines the kind of a AnnotationValue.
+type ValueKind int
+
+// Contains the different kinds of annotation values.
+const (
+	IdentValue ValueKind = iota
+	StringValue
+	NumberValue
+	BoolValue
+	ListValue
+	MapValue
+)
+
+var valueKindNames = map[ValueKind]string{
+	IdentValue:  "ident",
+	StringValue: "string",
+	NumberValue: "number",
+	BoolValue:   "bool",
+	ListValue:   "list",
+	MapValue:    "map",
+}
+
+// String returns the name of the kind.
+func (k ValueKind) String() string {
+	if name, ok := valueKindNames[k]; ok {
+		return name
+	}
+
+	return valueKindNames[IdentValue]
+}
+
+// AnnotationValue defines a value within the arguments of a annotation, which is either a
+// unquoted word (e.g `users`), a quoted string with escapes (e.g `"a, b"` or `'jug'`), a raw
+// string quoted with backticks, a number, a boolean, a list (e.g `[a, "b"]`) or a map
+// (e.g `{name: "bob", tags: [a, b]}`).
+type AnnotationValue struct {
+	Kind ValueKind `json:"kind"`
+
+	// Raw is the text of the value as written within the annotation.
+	Raw string `json:"raw"`
+
+	// Text is the decoded content of a quoted string, else the same as Raw.
+	Text string `json:"text"`
+
+	Items   []AnnotationValue          `json:"items,omitempty"`
+	Entries map[string]AnnotationValue `json:"entries,omitempty"`
+}
+
+// String returns the text of the value, with quoted strings decoded.
+func (v AnnotationValue) String() string {
+	return v.Text
+}
+
+// Int returns the value as a integer, it accepts the forms supported by strconv.ParseInt
+// with a base of 0, e.g `10`, `0x1F`, `1_000`.
+func (v AnnotationValue) Int() (int64, error) {
+	value, err := strconv.ParseInt(v.Text, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Value %q is not a integer", v.Raw)
+	}
+
+	return value, nil
+}
+
+// Float returns the value as a float.
+func (v AnnotationValue) Float() (float64, error) {
+	value, err := strconv.ParseFloat(v.Text, 64)
+	if err != nil {
+		if ivalue, ierr := v.Int(); ierr == nil {
+			return float64(ivalue), nil
+		}
+
+		return 0, fmt.Errorf("Value %q is not a number", v.Raw)
+	}
+
+	return value, nil
+}
+
+// Bool returns the value as a boolean, it accepts the forms supported by strconv.ParseBool.
+func (v AnnotationValue) Bool() (bool, error) {
+	value, err := strconv.ParseBool(v.Text)
+	if err != nil {
+		return false, fmt.Errorf("Value %q is not a boolean", v.Raw)
+	}
+
+	return value, nil
+}
+
+// List returns the items of the value if a list, else a list containing the value.
+func (v AnnotationValue) List() []AnnotationValue {
+	if v.Kind == ListValue {
+		return v.Items
+	}
+
+	return []AnnotationValue{v}
+}
+
+// Strings returns the text of the items of the value as returned by AnnotationValue.List.
+func (v AnnotationValue) Strings() []string {
+	items := v.List()
+
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+
+	return texts
+}
+
+// Map returns the entries of the value, it returns an error if the value is not a map.
+func (v AnnotationValue) Map() (map[string]AnnotationValue, error) {
+	if v.Kind != MapValue {
+		return nil, fmt.Errorf("Value %q is not a map", v.Raw)
+	}
+
+	return v.Entries, nil
+}
+
+// Keys returns the sorted keys of the entries of a map value.
+func (v AnnotationValue) Keys() []string {
+	keys := make([]string, 0, len(v.Entries))
+	for key := range v.Entries {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Interface returns the value as a Go value: strings and words as string, numbers as int64
+// or float64, booleans as bool, lists as []interface{} and maps as map[string]interface{}.
+func (v AnnotationValue) Interface() interface{} {
+	switch v.Kind {
+	case NumberValue:
+		if value, err := v.Int(); err == nil {
+			return value
+		}
+
+		value, _ := v.Float()
+		return value
+	case BoolValue:
+		value, _ := v.Bool()
+		return value
+	case ListValue:
+		items := make([]interface{}, 0, len(v.Items))
+		for _, item := range v.Items {
+			items = append(items, item.Interface())
+		}
+
+		return items
+	case MapValue:
+		entries := make(map[string]interface{}, len(v.Entries))
+		for key, item := range v.Entries {
+			entries[key] = item.Interface()
+		}
+
+		return entries
+	default:
+		return v.Text
+	}
+}
+
+//===========================================================================================================
+
+// errIncomplete is returned by the argument parser when the arguments end before a opened
+// list, map or string is closed.
+var errIncomplete = errors.New("incomplete arguments")
+
+// errUnclosed is returned by the argument parser when the arguments end without the closing ")".
+var errUnclosed = errors.New(`missing closing ")"`)
+
+// annotationArgument defines a argument parsed from the arguments of a annotation.
+type annotationArgument struct {
+	Raw   string
+	Key   string
+	Named bool
+	Value AnnotationValue
+}
+
+// argumentParser defines a parser for the arguments of a annotation, following the grammar:
+//
+//	arguments = "(" [ argument { "," argument } [ "," ] ] ")"
+//	argument  = value | key "=>" value
+//	value     = string | list | map | word
+//	list      = "[" [ value { "," value } [ "," ] ] "]"
+//	map       = "{" [ entry { "," entry } [ "," ] ] "}"
+//	entry     = key ( ":" | "=>" ) value
+//	string    = '"' ... '"' | "'" ... "'" | "`" ... "`"
+//
+// A word is any text up to the next "," or closing bracket not nested within brackets, which is
+// read as a number or boolean if it parses as one.
+type argumentParser struct {
+	src string
+	pos int
+}
+
+// parseArguments parses the arguments within the giving text, which starts after the opening "("
+// of the annotation. It returns errUnclosed with the arguments read if the closing ")" is missing.
+func parseArguments(src string) ([]annotationArgument, error) {
+	parser := &argumentParser{src: src}
+
+	var args []annotationArgument
+	for {
+		parser.skipSpaces()
+
+		if parser.done() {
+			return args, errUnclosed
+		}
+
+		if parser.peek() == ')' {
+			parser.pos++
+			return args, nil
+		}
+
+		start := parser.pos
+
+		value, err := parser.parseValue(false)
+		if err != nil {
+			return args, err
+		}
+
+		arg := annotationArgument{Value: value}
+
+		parser.skipSpaces()
+		if parser.consume("=>") {
+			arg.Named = true
+			arg.Key = value.Text
+
+			parser.skipSpaces()
+			if arg.Value, err = parser.parseValue(false); err != nil {
+				return args, err
+			}
+		}
+
+		arg.Raw = strings.TrimSpace(parser.src[start:parser.pos])
+		args = append(args, arg)
+
+		parser.skipSpaces()
+
+		switch {
+		case parser.done():
+			return args, errUnclosed
+		case parser.peek() == ',':
+			parser.pos++
+		case parser.peek() == ')':
+		default:
+			return args, parser.errorf("unexpected %q after argument %q", parser.peek(), arg.Raw)
+		}
+	}
+}
+
+// parseValueText parses the giving text as a single value.
+func parseValueText(src string) (AnnotationValue, error) {
+	parser := &argumentParser{src: strings.TrimSpace(src)}
+
+	value, err := parser.parseValue(false)
+	if err != nil {
+		return value, err
+	}
+
+	if !parser.done() {
+		return value, parser.errorf("unexpected %q after value", parser.peek())
+	}
+
+	return value, nil
+}
+
+// parseValue parses the value at the current position, words within map keys end at ":".
+func (p *argumentParser) parseValue(key bool) (AnnotationValue, error) {
+	if p.done() {
+		return AnnotationValue{}, errIncomplete
+	}
+
+	start := p.pos
+
+	switch p.peek() {
+	case '"', '\'', '`':
+		text, err := p.parseString()
+		if err != nil {
+			return AnnotationValue{}, err
+		}
+
+		return AnnotationValue{Kind: StringValue, Raw: p.src[start:p.pos], Text: text}, nil
+	case '[':
+		return p.parseList()
+	case '{':
+		return p.parseMap()
+	}
+
+	word, err := p.parseWord(key)
+	if err != nil {
+		return AnnotationValue{}, err
+	}
+
+	if word == "" {
+		if p.done() {
+			return AnnotationValue{}, errIncomplete
+		}
+
+		return AnnotationValue{}, p.errorf("expected value, found %q", p.peek())
+	}
+
+	value := AnnotationValue{Kind: IdentValue, Raw: word, Text: word}
+
+	if word == "true" || word == "false" {
+		value.Kind = BoolValue
+	} else if _, err := value.Float(); err == nil && isNumeric(word) {
+		value.Kind = NumberValue
+	}
+
+	return value, nil
+}
+
+// parseString parses a quoted string, decoding it's escapes. Strings quoted with "`" have
+// no escapes.
+func (p *argumentParser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+
+	if quote == '`' {
+		end := strings.IndexByte(p.src[p.pos:], '`')
+		if end == -1 {
+			p.pos = len(p.src)
+			return "", errIncomplete
+		}
+
+		text := p.src[p.pos : p.pos+end]
+		p.pos += end + 1
+		return text, nil
+	}
+
+	var text strings.Builder
+	for {
+		if p.done() {
+			return "", errIncomplete
+		}
+
+		if p.src[p.pos] == quote {
+			p.pos++
+			return text.String(), nil
+		}
+
+		value, _, tail, err := strconv.UnquoteChar(p.src[p.pos:], quote)
+		if err != nil {
+			return "", p.errorf("invalid escape in string")
+		}
+
+		text.WriteRune(value)
+		p.pos = len(p.src) - len(tail)
+	}
+}
+
+// parseList parses a list of values.
+func (p *argumentParser) parseList() (AnnotationValue, error) {
+	start := p.pos
+	p.pos++
+
+	value := AnnotationValue{Kind: ListValue, Items: []AnnotationValue{}}
+	for {
+		p.skipSpaces()
+
+		if p.done() {
+			return value, errIncomplete
+		}
+
+		if p.peek() == ']' {
+			p.pos++
+			value.Raw = p.src[start:p.pos]
+			value.Text = value.Raw
+			return value, nil
+		}
+
+		item, err := p.parseValue(false)
+		if err != nil {
+			return value, err
+		}
+
+		value.Items = append(value.Items, item)
+
+		if err := p.separator(']'); err != nil {
+			return value, err
+		}
+	}
+}
+
+// parseMap parses a map of values, keyed by words or strings.
+func (p *argumentParser) parseMap() (AnnotationValue, error) {
+	start := p.pos
+	p.pos++
+
+	value := AnnotationValue{Kind: MapValue, Entries: make(map[string]AnnotationValue)}
+	for {
+		p.skipSpaces()
+
+		if p.done() {
+			return value, errIncomplete
+		}
+
+		if p.peek() == '}' {
+			p.pos++
+			value.Raw = p.src[start:p.pos]
+			value.Text = value.Raw
+			return value, nil
+		}
+
+		key, err := p.parseValue(true)
+		if err != nil {
+			return value, err
+		}
+
+		p.skipSpaces()
+		if !p.consume(":") && !p.consume("=>") {
+			if p.done() {
+				return value, errIncomplete
+			}
+
+			return value, p.errorf("expected \":\" after map key %q", key.Raw)
+		}
+
+		p.skipSpaces()
+
+		item, err := p.parseValue(false)
+		if err != nil {
+			return value, err
+		}
+
+		value.Entries[key.Text] = item
+
+		if err := p.separator('}'); err != nil {
+			return value, err
+		}
+	}
+}
+
+// separator consumes the "," between items of a list or map, leaving the closing bracket.
+func (p *argumentParser) separator(closing byte) error {
+	p.skipSpaces()
+
+	switch {
+	case p.done():
+		return errIncomplete
+	case p.peek() == ',':
+		p.pos++
+		return nil
+	case p.peek() == closing:
+		return nil
+	default:
+		return p.errorf("expected \",\" or %q, found %q", closing, p.peek())
+	}
+}
+
+// parseWord parses a unquoted word up to the next ",", "=>" or closing bracket which is not
+// nested within brackets, keys of maps also end at ":".
+func (p *argumentParser) parseWord(key bool) (string, error) {
+	start := p.pos
+
+	var depth int
+	for !p.done() {
+		char := p.peek()
+
+		if depth == 0 {
+			if char == ',' || char == ')' || char == ']' || char == '}' || strings.HasPrefix(p.src[p.pos:], "=>") {
+				break
+			}
+
+			if key && char == ':' {
+				break
+			}
+		}
+
+		switch char {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+
+		p.pos++
+	}
+
+	if depth > 0 {
+		return "", errIncomplete
+	}
+
+	return strings.TrimSpace(p.src[start:p.pos]), nil
+}
+
+// isNumeric returns true/false if the word starts as a number, which excludes words like
+// `Inf` and `NaN` accepted by strconv.ParseFloat.
+func isNumeric(word string) bool {
+	word = strings.TrimLeft(word, "+-.")
+	return word != "" && unicode.IsDigit(rune(word[0]))
+}
+
+func (p *argumentParser) skipSpaces() {
+	for !p.done() && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *argumentParser) consume(token string) bool {
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+
+	return false
+}
+
+func (p *argumentParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *argumentParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *argumentParser) errorf(message string, args ...interface{}) error {
+	return fmt.Errorf("column %d: %s", p.pos+1, fmt.Sprintf(message, args...))
+}
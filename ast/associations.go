@@ -0,0 +1,180 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/influx6/faux/metrics"
+)
+
+// ResolveAssociation returns the association with the struct, interface or type declaration
+// referenced by it's TypeName. Names are looked up within the package, names qualified by a
+// import name or path (e.g `models.User`) within the imported package of the declaration or
+// the package of the set with that name or path. Associations already resolved are returned as is.
+func ResolveAssociation(association AnnotationAssociationDeclaration, declr PackageDeclaration, pkg Package, set ...Package) (AnnotationAssociationDeclaration, error) {
+	if association.Resolved() {
+		return association, nil
+	}
+
+	typeName := strings.TrimPrefix(association.TypeName, "*")
+
+	var qualifier string
+	if index := strings.LastIndex(typeName, "."); index != -1 {
+		qualifier, typeName = typeName[:index], typeName[index+1:]
+	}
+
+	var candidates []Package
+
+	if qualifier == "" || qualifier == pkg.Name || qualifier == pkg.Path {
+		candidates = append(candidates, pkg)
+	}
+
+	if qualifier != "" {
+		if imported, ok := declr.ImportedPackageFor(qualifier); ok {
+			candidates = append(candidates, imported)
+		}
+
+		if imported, ok := declr.ImportedPackages[qualifier]; ok {
+			candidates = append(candidates, imported)
+		}
+
+		for _, other := range set {
+			if other.Path == qualifier || other.Name == qualifier {
+				candidates = append(candidates, other)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if str, ok := candidate.StructFor(typeName); ok {
+			association.Kind = structKind
+			association.Struct = &str
+			return association, nil
+		}
+
+		if inter, ok := candidate.InterfaceFor(typeName); ok {
+			association.Kind = interfaceKind
+			association.Interface = &inter
+			return association, nil
+		}
+
+		if typ, ok := candidate.TypeFor(typeName); ok {
+			association.Kind = typeKind
+			association.Type = &typ
+			return association, nil
+		}
+	}
+
+	return association, fmt.Errorf("associated type %q for @%s not found", association.TypeName, association.Annotation)
+}
+
+// ResolveAssociations resolves the associations of all declarations within the packages, whose
+// types may be declared in any package of the set. Associations which can not be resolved are
+// left unresolved, for the AnnotationRegistry to report when their annotation is generated.
+func (pkgs Packages) ResolveAssociations() {
+	for _, pkg := range pkgs {
+		for _, declr := range pkg.Packages {
+			for _, inter := range declr.Interfaces {
+				resolveAssociations(inter.Associations, declr, pkg, pkgs)
+			}
+
+			for _, str := range declr.Structs {
+				resolveAssociations(str.Associations, declr, pkg, pkgs)
+			}
+
+			for _, fun := range declr.Functions {
+				resolveAssociations(fun.Associations, declr, pkg, pkgs)
+			}
+
+			for _, typ := range declr.Types {
+				resolveAssociations(typ.Associations, declr, pkg, pkgs)
+			}
+
+			for _, variable := range declr.Variables {
+				resolveAssociations(variable.Associations, declr, pkg, pkgs)
+			}
+		}
+	}
+}
+
+// resolveAssociations resolves the associations in place, as the map is shared by all
+// copies of the declaration.
+func resolveAssociations(associations map[string]AnnotationAssociationDeclaration, declr PackageDeclaration, pkg Package, set Packages) {
+	for key, association := range associations {
+		if resolved, err := ResolveAssociation(association, declr, pkg, set...); err == nil {
+			associations[key] = resolved
+		}
+	}
+}
+
+// associate returns the annotation with the association declared for it on the declaration,
+// resolved from the package of the declaration and it's imports if not already resolved. The
+// error of a association which can not be resolved is positioned at it's `@associates`.
+func associate(annotation AnnotationDeclaration, associations map[string]AnnotationAssociationDeclaration, declr PackageDeclaration, pkg Package, location token.Position) (AnnotationDeclaration, error) {
+	name := strings.TrimPrefix(annotation.Name, "@")
+
+	for _, association := range associations {
+		if association.Annotation != name {
+			continue
+		}
+
+		resolved, err := ResolveAssociation(association, declr, pkg)
+		if err != nil {
+			return annotation, DiagnosticFor(err, association.Record, location)
+		}
+
+		annotation.Association = &resolved
+		return annotation, nil
+	}
+
+	return annotation, nil
+}
+
+// AssociationAnnotation defines the name of the annotation which associates a type with another
+// annotation of the declaration, e.g `@associates(@mongo, NewUser, User)`.
+const AssociationAnnotation = "associates"
+
+// readAssociations returns the annotations of a declaration without it's associations, which are
+// returned keyed by the annotation they are declared for, with the diagnostics of incomplete ones.
+func readAssociations(log metrics.Metrics, dir string, annotations []AnnotationDeclaration) ([]AnnotationDeclaration, map[string]AnnotationAssociationDeclaration, Diagnostics) {
+	var others []AnnotationDeclaration
+	var diagnostics Diagnostics
+
+	associations := make(map[string]AnnotationAssociationDeclaration, 0)
+
+	for _, item := range annotations {
+		if strings.TrimPrefix(item.Name, "@") != AssociationAnnotation {
+			others = append(others, item)
+			continue
+		}
+
+		log.Emit(
+			metrics.Info("Association found"),
+			metrics.With("dir", dir),
+			metrics.With("association", item.Arguments),
+		)
+
+		if len(item.Arguments) < 3 {
+			log.Emit(metrics.Error(errors.New("Association Annotation in Declaration is incomplete: Expects 3 elements")), metrics.With("dir", dir), metrics.With("association", item.Arguments))
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity:   SeverityError,
+				Position:   item.Position,
+				Annotation: item.Name,
+				Message:    fmt.Sprintf("incomplete association, expects 3 arguments but got %d", len(item.Arguments)),
+			})
+			continue
+		}
+
+		associations[item.Arguments[0]] = AnnotationAssociationDeclaration{
+			Record:     item,
+			Template:   item.Template,
+			Action:     item.Arguments[1],
+			TypeName:   item.Arguments[2],
+			Annotation: strings.TrimPrefix(item.Arguments[0], "@"),
+		}
+	}
+
+	return others, associations, diagnostics
+}
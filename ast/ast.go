@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"io"
 	"math/rand"
 	"os"
@@ -70,6 +71,7 @@ var (
 		"int64":       true,
 		"uint":        true,
 		"uint8":       true,
+		"uint16":      true,
 		"uint32":      true,
 		"uint64":      true,
 		"uintptr":     true,
@@ -78,6 +80,8 @@ var (
 		"complex128":  true,
 		"complex64":   true,
 		"error":       true,
+		"any":         true,
+		"comparable":  true,
 		"struct":      true,
 		"interface":   true,
 		"interface{}": true,
@@ -152,6 +156,8 @@ type Package struct {
 	BuildPkg     *build.Package
 	Packages     []PackageDeclaration
 	TestPackages []PackageDeclaration
+	TypesPackage *types.Package
+	TypesInfo    *types.Info
 }
 
 // HasFunctionFor returns true/false if the giving Struct Declaration has the giving function name.
@@ -330,7 +336,30 @@ type PackageDeclaration struct {
 	Functions        []FuncDeclaration
 	Variables        []VariableDeclaration
 	ObjectFunc       map[*ast.Object][]FuncDeclaration
+	TypesPackage     *types.Package
+	TypesInfo        *types.Info
+	Diagnostics      Diagnostics
 	importedloaded   bool
+	astPkg           *ast.Package
+	tokenFiles       *token.FileSet
+	scope            *packageScope
+}
+
+// packageScope defines the state shared by the PackageDeclarations of all files of a package.
+type packageScope struct {
+	// ctx is the build.Context the package was loaded with, used to load it's imports.
+	ctx build.Context
+
+	// declrs contains the PackageDeclaration of each file of the package by it's path.
+	declrs map[string]*PackageDeclaration
+}
+
+// newPackageScope returns a packageScope for a package loaded with the build.Context.
+func newPackageScope(ctx build.Context) *packageScope {
+	return &packageScope{
+		ctx:    ctx,
+		declrs: make(map[string]*PackageDeclaration),
+	}
 }
 
 // HasFunctionFor returns true/false if the giving Struct Declaration has the giving function name.
@@ -518,13 +547,21 @@ type VariableDeclaration struct {
 	Comments        string
 	File            string
 	Position        token.Pos
+	Location        token.Position
 	Object          *ast.ValueSpec
 	GenObj          *ast.GenDecl
+	TypesObject     types.Object
+	TypesType       types.Type
 	Declr           *PackageDeclaration
 	Annotations     []AnnotationDeclaration
 	Associations    map[string]AnnotationAssociationDeclaration
 }
 
+// IsConstant returns true/false if the variable was declared as a constant.
+func (v VariableDeclaration) IsConstant() bool {
+	return v.GenObj != nil && v.GenObj.Tok == token.CONST
+}
+
 // StructDeclaration defines a type which holds annotation data for a giving struct type declaration.
 type StructDeclaration struct {
 	From            int
@@ -541,6 +578,10 @@ type StructDeclaration struct {
 	Object          *ast.TypeSpec
 	GenObj          *ast.GenDecl
 	Position        token.Pos
+	Location        token.Position
+	TypeParams      TypeParams
+	TypesObject     types.Object
+	TypesType       types.Type
 	Declr           *PackageDeclaration
 	Annotations     []AnnotationDeclaration
 	Associations    map[string]AnnotationAssociationDeclaration
@@ -592,6 +633,10 @@ type TypeDeclaration struct {
 	Object          *ast.TypeSpec
 	GenObj          *ast.GenDecl
 	Position        token.Pos
+	Location        token.Position
+	TypeParams      TypeParams
+	TypesObject     types.Object
+	TypesType       types.Type
 	Declr           *PackageDeclaration
 	Annotations     []AnnotationDeclaration
 	Associations    map[string]AnnotationAssociationDeclaration
@@ -632,15 +677,20 @@ type FuncDeclaration struct {
 	Source              string
 	Comments            string
 	Position            token.Pos
+	Location            token.Position
 	TypeDeclr           ast.Decl
 	FuncDeclr           *ast.FuncDecl
 	Type                *ast.FuncType
 	Reciever            *ast.Object
 	RecieverIdent       *ast.Ident
 	RecieverPointer     *ast.StarExpr
+	RecieverTypeParams  []string
+	TypeParams          TypeParams
 	FuncType            *ast.FieldList
 	Returns             *ast.FieldList
 	Arguments           *ast.FieldList
+	TypesObject         types.Object
+	TypesType           types.Type
 	Declr               *PackageDeclaration
 	Annotations         []AnnotationDeclaration
 	Associations        map[string]AnnotationAssociationDeclaration
@@ -696,6 +746,46 @@ func (fun FuncDeclaration) Definition(pkg *PackageDeclaration) (FunctionDefiniti
 	return GetFunctionDefinitionFromDeclaration(fun, pkg)
 }
 
+// Signature returns a gen.MethodSignatureDeclr for this function, documented with the comments of
+// the function excluding its annotations.
+func (fun FuncDeclaration) Signature(asFromOutside bool) (gen.MethodSignatureDeclr, error) {
+	def, err := fun.Definition(fun.Declr)
+	if err != nil {
+		return gen.MethodSignatureDeclr{}, err
+	}
+
+	var comments []string
+	for _, line := range strings.Split(fun.Comments, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "@") {
+			continue
+		}
+
+		comments = append(comments, line)
+	}
+
+	signature := def.Signature(asFromOutside)
+	signature.Comments = gen.Doc(strings.Join(comments, "\n"))
+	return signature, nil
+}
+
+// InterfaceFrom returns a gen.InterfaceDeclr for a interface of the giving name with the signatures
+// of the provided functions, which allows generating a narrowed interface for a subset of a struct's
+// methods.
+func InterfaceFrom(name gen.NameDeclr, comments io.WriterTo, asFromOutside bool, funcs ...FuncDeclaration) (gen.InterfaceDeclr, error) {
+	var methods []gen.MethodSignatureDeclr
+
+	for _, fun := range funcs {
+		signature, err := fun.Signature(asFromOutside)
+		if err != nil {
+			return gen.InterfaceDeclr{}, fmt.Errorf("Function %q: %s", fun.FuncName, err)
+		}
+
+		methods = append(methods, signature)
+	}
+
+	return gen.InterfaceType(name, comments, nil, nil, methods...), nil
+}
+
 // Functions defines a slice of FuncDeclaration.
 type Functions []FuncDeclaration
 
@@ -720,6 +810,18 @@ type AnnotationAssociationDeclaration struct {
 	Template   string
 	TypeName   string
 	Record     AnnotationDeclaration
+
+	// Kind is the kind of declaration TypeName resolved to (struct, interface or type) with
+	// the declaration set in one of Struct, Interface or Type (see ResolveAssociation).
+	Kind      string
+	Struct    *StructDeclaration    `json:"-"`
+	Interface *InterfaceDeclaration `json:"-"`
+	Type      *TypeDeclaration      `json:"-"`
+}
+
+// Resolved returns true/false if the declaration referenced by TypeName has been resolved.
+func (ad AnnotationAssociationDeclaration) Resolved() bool {
+	return ad.Kind != ""
 }
 
 // InterfaceDeclaration defines a type which holds annotation data for a giving interface type declaration.
@@ -738,12 +840,23 @@ type InterfaceDeclaration struct {
 	Object          *ast.TypeSpec
 	GenObj          *ast.GenDecl
 	Position        token.Pos
+	Location        token.Position
+	TypeParams      TypeParams
+	Constraints     []string
+	TypesObject     types.Object
+	TypesType       types.Type
 	Declr           *PackageDeclaration
 	methods         []FunctionDefinition
 	Annotations     []AnnotationDeclaration
 	Associations    map[string]AnnotationAssociationDeclaration
 }
 
+// IsConstraint returns true/false if the interface declares a type set (e.g `~int | ~string`),
+// which only allows it's use as a type parameter constraint.
+func (i InterfaceDeclaration) IsConstraint() bool {
+	return len(i.Constraints) != 0
+}
+
 // GetImports returns a map containing all import paths related to
 // types used for the methods of giving interface.
 func (i *InterfaceDeclaration) GetImports(pkg *PackageDeclaration, internal bool) map[string]string {
@@ -811,6 +924,9 @@ type ArgType struct {
 	ExType          string
 	IsReturn        bool
 	FromMethod      bool
+	Variadic        bool
+	TypeParam       bool
+	TypeArgs        []string
 	Package         string
 	IsStruct        bool
 	BaseType        bool
@@ -829,6 +945,8 @@ type ArgType struct {
 	ChanType        *ast.ChanType
 	PointerType     *ast.StarExpr
 	IdentType       *ast.Ident
+	GenericType     ast.Expr
+	TypesType       types.Type
 	Tags            []TagDeclaration
 	Pkg             *PackageDeclaration
 }
@@ -869,15 +987,123 @@ func (a ArgType) GetStructJSON() string {
 	return res
 }
 
+// TypeParamDeclaration defines a type which represents a type parameter of a generic type or
+// function declaration with it's constraint.
+type TypeParamDeclaration struct {
+	Name             string
+	Constraint       string
+	ConstraintObject ast.Expr
+	Field            *ast.Field
+}
+
+// TypeParams defines a slice type of TypeParamDeclaration.
+type TypeParams []TypeParamDeclaration
+
+// GetTypeParams returns the TypeParams declared by the giving type parameter list, which
+// is nil for non-generic declarations.
+func GetTypeParams(list *ast.FieldList) TypeParams {
+	if list == nil {
+		return nil
+	}
+
+	var params TypeParams
+
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			params = append(params, TypeParamDeclaration{
+				Name:             name.Name,
+				Constraint:       types.ExprString(field.Type),
+				ConstraintObject: field.Type,
+				Field:            field,
+			})
+		}
+	}
+
+	return params
+}
+
+// Names returns the names of the type parameters.
+func (tp TypeParams) Names() []string {
+	var names []string
+
+	for _, param := range tp {
+		names = append(names, param.Name)
+	}
+
+	return names
+}
+
+// Declr returns a gen.TypeParamsDeclr declaring the type parameters.
+func (tp TypeParams) Declr() gen.TypeParamsDeclr {
+	var params []gen.TypeParamDeclr
+
+	for _, param := range tp {
+		params = append(params, gen.TypeParam(gen.Name(param.Name), gen.Type(param.Constraint)))
+	}
+
+	return gen.TypeParams(params...)
+}
+
+// Instance returns a gen.TypeDeclr referencing the generic type of the giving name instantiated
+// with the type parameters, e.g as the receiver type of it's methods.
+func (tp TypeParams) Instance(name string) gen.TypeDeclr {
+	var args []gen.TypeDeclr
+
+	for _, param := range tp {
+		args = append(args, gen.Type(param.Name))
+	}
+
+	return gen.Instance(name, args...)
+}
+
 // FunctionDefinition defines a type to represent the function/method declarations of an
 // interface type.
 type FunctionDefinition struct {
-	Name      string
-	Args      []ArgType
-	Returns   []ArgType
-	Func      *ast.FuncType
-	Interface *ast.InterfaceType
-	Struct    *ast.StructType
+	Name           string
+	Args           []ArgType
+	Returns        []ArgType
+	Func           *ast.FuncType
+	Interface      *ast.InterfaceType
+	Struct         *ast.StructType
+	TypesSignature *types.Signature
+	Annotations    []AnnotationDeclaration
+}
+
+// AnnotationsFor returns all annotations with the giving name.
+func (fd FunctionDefinition) AnnotationsFor(typeName string) []AnnotationDeclaration {
+	typeName = strings.TrimPrefix(typeName, "@")
+
+	var found []AnnotationDeclaration
+
+	for _, item := range fd.Annotations {
+		if strings.TrimPrefix(item.Name, "@") != typeName {
+			continue
+		}
+
+		found = append(found, item)
+	}
+
+	return found
+}
+
+// GetAnnotation returns the first annotation with the giving name.
+func (fd FunctionDefinition) GetAnnotation(typeName string) (AnnotationDeclaration, bool) {
+	typeName = strings.TrimPrefix(typeName, "@")
+	for _, item := range fd.Annotations {
+		if strings.TrimPrefix(item.Name, "@") != typeName {
+			continue
+		}
+
+		return item, true
+	}
+
+	return AnnotationDeclaration{}, false
+}
+
+// HasAnnotation returns true/false if the method has the giving annotation.
+func (fd FunctionDefinition) HasAnnotation(typeName string) bool {
+	_, ok := fd.GetAnnotation(typeName)
+	return ok
 }
 
 // TotalReturns returns length of  function return set.
@@ -1098,6 +1324,70 @@ func (fd FunctionDefinition) ArgumentList(asFromOutside bool) string {
 	return strings.Join(args, ",")
 }
 
+// Params returns the arguments of the giving function as a gen.ParamsDeclr, using the types as seen
+// from outside the package if asFromOutside is true.
+func (fd FunctionDefinition) Params(asFromOutside bool) gen.ParamsDeclr {
+	var params []gen.ParamDeclr
+
+	for _, arg := range fd.Args {
+		params = append(params, arg.Param(asFromOutside))
+	}
+
+	return gen.Params(params...)
+}
+
+// Results returns the returns of the giving function as a gen.ResultsDeclr, using the types as seen
+// from outside the package if asFromOutside is true. Returns are only named if they were named in
+// the function's declaration.
+func (fd FunctionDefinition) Results(asFromOutside bool) gen.ResultsDeclr {
+	named := fd.Func != nil && fd.Func.Results != nil && len(fd.Func.Results.List) != 0 && len(fd.Func.Results.List[0].Names) != 0
+
+	var results []gen.ParamDeclr
+
+	for _, ret := range fd.Returns {
+		result := ret.Param(asFromOutside)
+		if !named {
+			result.Name = gen.Name("")
+		}
+
+		results = append(results, result)
+	}
+
+	return gen.Results(results...)
+}
+
+// Method returns a gen.MethodDeclr with the signature of the giving function for the provided receiver,
+// which allows generating implementations and mocks of interface methods. If no body is provided,
+// the method panics when called.
+func (fd FunctionDefinition) Method(receiver gen.ReceiverDeclr, asFromOutside bool, body ...io.WriterTo) gen.MethodDeclr {
+	if len(body) == 0 {
+		body = append(body, gen.Fmt("panic(%q)", fd.Name+" is not implemented"))
+	}
+
+	return gen.Method(receiver, gen.Name(fd.Name), fd.Params(asFromOutside), fd.Results(asFromOutside), body...)
+}
+
+// Signature returns a gen.MethodSignatureDeclr with the signature of the giving function, for use
+// within a gen.InterfaceDeclr.
+func (fd FunctionDefinition) Signature(asFromOutside bool) gen.MethodSignatureDeclr {
+	return gen.MethodSignature(gen.Name(fd.Name), nil, fd.Params(asFromOutside), fd.Results(asFromOutside))
+}
+
+// Param returns the giving argument as a gen.ParamDeclr, using the type as seen from outside the
+// package if asFromOutside is true.
+func (a ArgType) Param(asFromOutside bool) gen.ParamDeclr {
+	argType := a.Type
+	if asFromOutside {
+		argType = a.ExType
+	}
+
+	if a.Variadic {
+		return gen.VariadicParam(gen.Name(a.Name), gen.Type(strings.TrimPrefix(argType, "...")))
+	}
+
+	return gen.Param(gen.Name(a.Name), gen.Type(argType))
+}
+
 //===========================================================================================================
 
 // Fields defines a slice type of FieldDeclaration.
@@ -1169,6 +1459,45 @@ type FieldDeclaration struct {
 	Struct        *ast.StructType
 	Tags          []TagDeclaration
 	Arg           ArgType
+	TypesObject   types.Object
+	Annotations   []AnnotationDeclaration
+}
+
+// AnnotationsFor returns all annotations with the giving name.
+func (f FieldDeclaration) AnnotationsFor(typeName string) []AnnotationDeclaration {
+	typeName = strings.TrimPrefix(typeName, "@")
+
+	var found []AnnotationDeclaration
+
+	for _, item := range f.Annotations {
+		if strings.TrimPrefix(item.Name, "@") != typeName {
+			continue
+		}
+
+		found = append(found, item)
+	}
+
+	return found
+}
+
+// GetAnnotation returns the first annotation with the giving name.
+func (f FieldDeclaration) GetAnnotation(typeName string) (AnnotationDeclaration, bool) {
+	typeName = strings.TrimPrefix(typeName, "@")
+	for _, item := range f.Annotations {
+		if strings.TrimPrefix(item.Name, "@") != typeName {
+			continue
+		}
+
+		return item, true
+	}
+
+	return AnnotationDeclaration{}, false
+}
+
+// HasAnnotation returns true/false if the field has the giving annotation.
+func (f FieldDeclaration) HasAnnotation(typeName string) bool {
+	_, ok := f.GetAnnotation(typeName)
+	return ok
 }
 
 // GetFields returns all fields associated with the giving struct but skips
@@ -1201,6 +1530,8 @@ func GetFields(str StructDeclaration, pkg *PackageDeclaration) []FieldDeclaratio
 		field.Field = item
 		field.FieldName = arg.Name
 		field.FieldTypeName = arg.Type
+		field.TypesObject = pkg.fieldObjectOf(item)
+		field.Annotations, _ = ReadFieldAnnotations(pkg.tokenFiles, item)
 
 		if len(item.Names) == 0 {
 			field.Exported = true
@@ -1300,6 +1631,16 @@ func GetIdentName(field *ast.Field) (*ast.Ident, error) {
 // GetArgTypeFromField returns a ArgType that writes out the representation of the giving variable name or decleration ast.Field
 // associated with the giving package. It returns an error if it does not know the type.
 func GetArgTypeFromField(retCounter int, varPrefix string, method string, targetFile string, result *ast.Field, pkg *PackageDeclaration) (ArgType, error) {
+	arg, err := getArgTypeFromField(retCounter, varPrefix, method, targetFile, result, pkg)
+	if err != nil {
+		return arg, err
+	}
+
+	arg.TypesType = pkg.typeOf(result.Type)
+	return arg, nil
+}
+
+func getArgTypeFromField(retCounter int, varPrefix string, method string, targetFile string, result *ast.Field, pkg *PackageDeclaration) (ArgType, error) {
 	var tags []TagDeclaration
 
 	if result.Tag != nil {
@@ -1376,6 +1717,7 @@ func GetArgTypeFromField(retCounter int, varPrefix string, method string, target
 			TypeObject: iobj.Obj,
 			Package:    resPkg,
 			BaseType:   defaultresType,
+			TypeParam:  isTypeParam(iobj),
 		}
 
 		if iobj.Obj != nil && iobj.Obj.Decl != nil {
@@ -1736,11 +2078,66 @@ func GetArgTypeFromField(retCounter int, varPrefix string, method string, target
 		}
 
 		return arg, nil
+
+	case *ast.Ellipsis:
+		elem := *result
+		elem.Type = iobj.Elt
+
+		arg, err := GetArgTypeFromField(retCounter, varPrefix, method, targetFile, &elem, pkg)
+		if err != nil {
+			return ArgType{}, err
+		}
+
+		arg.Variadic = true
+		arg.Type = getName(iobj)
+		arg.ExType = getNameAsFromOuter(iobj, filepath.Base(pkg.Package))
+		return arg, nil
+
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		base, indices := genericIndices(iobj)
+
+		elem := *result
+		elem.Type = base
+
+		arg, err := GetArgTypeFromField(retCounter, varPrefix, method, targetFile, &elem, pkg)
+		if err != nil {
+			return ArgType{}, err
+		}
+
+		for _, index := range indices {
+			arg.TypeArgs = append(arg.TypeArgs, getName(index))
+		}
+
+		arg.GenericType = iobj
+		arg.Type = getName(iobj)
+		arg.ExType = getNameAsFromOuter(iobj, filepath.Base(pkg.Package))
+		return arg, nil
 	}
 
 	return ArgType{}, errors.New("Unknown Field type, only variable type declaration wanted")
 }
 
+// splitFieldNames returns the fields with each field declaring multiple names (e.g `a, b int`)
+// split into a field per name.
+func splitFieldNames(fields []*ast.Field) []*ast.Field {
+	var splits []*ast.Field
+
+	for _, field := range fields {
+		if len(field.Names) < 2 {
+			splits = append(splits, field)
+			continue
+		}
+
+		for _, name := range field.Names {
+			named := *field
+			named.Names = []*ast.Ident{name}
+			splits = append(splits, &named)
+		}
+	}
+
+	return splits
+}
+
 // GetFunctionDefinitionFromField returns a FunctionDefinition representing a giving function.
 func GetFunctionDefinitionFromField(method *ast.Field, pkg *PackageDeclaration) (FunctionDefinition, error) {
 	if len(method.Names) == 0 {
@@ -1757,7 +2154,7 @@ func GetFunctionDefinitionFromField(method *ast.Field, pkg *PackageDeclaration)
 
 	if ftype.Results != nil {
 		var retCounter int
-		for _, result := range ftype.Results.List {
+		for _, result := range splitFieldNames(ftype.Results.List) {
 			retCounter++
 			arg, err := GetArgTypeFromField(retCounter, "ret", nameIdent.Name, pkg.File, result, pkg)
 			if err != nil {
@@ -1772,7 +2169,7 @@ func GetFunctionDefinitionFromField(method *ast.Field, pkg *PackageDeclaration)
 
 	if ftype.Params != nil {
 		var varCounter int
-		for _, param := range ftype.Params.List {
+		for _, param := range splitFieldNames(ftype.Params.List) {
 			varCounter++
 			arg, err := GetArgTypeFromField(varCounter, "var", nameIdent.Name, pkg.File, param, pkg)
 			if err != nil {
@@ -1784,11 +2181,15 @@ func GetFunctionDefinitionFromField(method *ast.Field, pkg *PackageDeclaration)
 		}
 	}
 
+	annotations, _ := ReadFieldAnnotations(pkg.tokenFiles, method)
+
 	return FunctionDefinition{
-		Func:    ftype,
-		Returns: returns,
-		Args:    arguments,
-		Name:    nameIdent.Name,
+		Func:           ftype,
+		Returns:        returns,
+		Args:           arguments,
+		Name:           nameIdent.Name,
+		Annotations:    annotations,
+		TypesSignature: pkg.signatureOf(nameIdent),
 	}, nil
 }
 
@@ -1798,7 +2199,7 @@ func GetFunctionDefinitionFromDeclaration(funcObj FuncDeclaration, pkg *PackageD
 
 	if funcObj.Type.Results != nil {
 		var retCounter int
-		for _, result := range funcObj.Type.Results.List {
+		for _, result := range splitFieldNames(funcObj.Type.Results.List) {
 			retCounter++
 			arg, err := GetArgTypeFromField(retCounter, "ret", funcObj.FuncName, funcObj.File, result, pkg)
 			if err != nil {
@@ -1813,7 +2214,7 @@ func GetFunctionDefinitionFromDeclaration(funcObj FuncDeclaration, pkg *PackageD
 
 	if funcObj.Type.Params != nil {
 		var varCounter int
-		for _, param := range funcObj.Type.Params.List {
+		for _, param := range splitFieldNames(funcObj.Type.Params.List) {
 			varCounter++
 			arg, err := GetArgTypeFromField(varCounter, "var", funcObj.FuncName, funcObj.File, param, pkg)
 			if err != nil {
@@ -1830,44 +2231,70 @@ func GetFunctionDefinitionFromDeclaration(funcObj FuncDeclaration, pkg *PackageD
 	defs.Returns = returns
 	defs.Args = arguments
 	defs.Name = funcObj.FuncName
+	defs.Annotations = funcObj.Annotations
+
+	if signature, ok := funcObj.TypesType.(*types.Signature); ok {
+		defs.TypesSignature = signature
+	}
 
 	return defs, nil
 }
 
 // GetInterfaceFunctions returns a slice of FunctionDefinitions retrieved from the provided
-// interface type object.
+// interface type object. Embedded interfaces are flattened transitively, including those
+// declared in other files of the package, in imported packages and the predeclared error.
 func GetInterfaceFunctions(intr *ast.InterfaceType, pkg *PackageDeclaration) []FunctionDefinition {
+	return getInterfaceFunctions(intr, pkg, make(map[*ast.InterfaceType]bool))
+}
+
+func getInterfaceFunctions(intr *ast.InterfaceType, pkg *PackageDeclaration, visited map[*ast.InterfaceType]bool) []FunctionDefinition {
+	if visited[intr] {
+		return nil
+	}
+
+	visited[intr] = true
+
 	var defs []FunctionDefinition
 
+	seen := make(map[string]bool)
+	add := func(def FunctionDefinition) {
+		if seen[def.Name] {
+			return
+		}
+
+		seen[def.Name] = true
+		defs = append(defs, def)
+	}
+
 	for _, method := range intr.Methods.List {
 		if len(method.Names) != 0 {
 			if def, err := GetFunctionDefinitionFromField(method, pkg); err == nil {
 				def.Interface = intr
-				defs = append(defs, def)
+				add(def)
 			}
 			continue
 		}
 
-		ident, ok := method.Type.(*ast.Ident)
-		if !ok {
-			continue
-		}
-
-		if ident == nil || ident.Obj == nil || ident.Obj.Decl == nil {
+		if ident, ok := method.Type.(*ast.Ident); ok && ident.Name == "error" && ident.Obj == nil {
+			for _, def := range errorMethods(pkg) {
+				add(def)
+			}
 			continue
 		}
 
-		identDecl, ok := ident.Obj.Decl.(*ast.TypeSpec)
+		spec, declr, ok := pkg.resolveType(method.Type)
 		if !ok {
 			continue
 		}
 
-		identIntr, ok := identDecl.Type.(*ast.InterfaceType)
+		identIntr, ok := spec.Type.(*ast.InterfaceType)
 		if !ok {
 			continue
 		}
 
-		defs = append(defs, GetInterfaceFunctions(identIntr, pkg)...)
+		for _, def := range getInterfaceFunctions(identIntr, declr, visited) {
+			add(def)
+		}
 	}
 
 	return defs
@@ -1985,6 +2412,10 @@ func getNameAsFromOuter(item interface{}, basePkg string) string {
 			return di.Name
 		}
 
+		if isTypeParam(di) {
+			return di.Name
+		}
+
 		return fmt.Sprintf("%s.%s", basePkg, di.Name)
 	case *ast.ArrayType:
 		if di.Len != nil {
@@ -2000,11 +2431,46 @@ func getNameAsFromOuter(item interface{}, basePkg string) string {
 		return fmt.Sprintf("[]%s", getNameAsFromOuter(di.Elt, basePkg))
 	case *ast.ChanType:
 		return fmt.Sprintf("chan %s", getNameAsFromOuter(di.Value, basePkg))
+	case *ast.Ellipsis:
+		return fmt.Sprintf("...%s", getNameAsFromOuter(di.Elt, basePkg))
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		base, indices := genericIndices(di.(ast.Expr))
+
+		var args []string
+		for _, index := range indices {
+			args = append(args, getNameAsFromOuter(index, basePkg))
+		}
+
+		return fmt.Sprintf("%s[%s]", getNameAsFromOuter(base, basePkg), strings.Join(args, ", "))
 	default:
 		return ""
 	}
 }
 
+// genericIndices returns the generic type and the type arguments of the giving instantiation,
+// e.g `List` and `[T]` for `List[T]`.
+func genericIndices(expr ast.Expr) (ast.Expr, []ast.Expr) {
+	switch di := expr.(type) {
+	case *ast.IndexExpr:
+		return di.X, []ast.Expr{di.Index}
+	case *ast.IndexListExpr:
+		return di.X, di.Indices
+	default:
+		return expr, nil
+	}
+}
+
+// isTypeParam returns true/false if the identifier references a type parameter, which the
+// parser resolves to the field of the type parameter list that declares it.
+func isTypeParam(ident *ast.Ident) bool {
+	if ident.Obj == nil || ident.Obj.Kind != ast.Typ {
+		return false
+	}
+
+	_, ok := ident.Obj.Decl.(*ast.Field)
+	return ok
+}
+
 func getName(item interface{}) string {
 	switch di := item.(type) {
 	case *ast.InterfaceType:
@@ -2041,6 +2507,17 @@ func getName(item interface{}) string {
 		return fmt.Sprintf("[]%s", getName(di.Elt))
 	case *ast.ChanType:
 		return fmt.Sprintf("chan %s", getName(di.Value))
+	case *ast.Ellipsis:
+		return fmt.Sprintf("...%s", getName(di.Elt))
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		base, indices := genericIndices(di.(ast.Expr))
+
+		var args []string
+		for _, index := range indices {
+			args = append(args, getName(index))
+		}
+
+		return fmt.Sprintf("%s[%s]", getName(base), strings.Join(args, ", "))
 	default:
 		return ""
 	}
@@ -2,6 +2,7 @@ package ast_test
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/influx6/faux/tests"
@@ -110,3 +111,155 @@ func TestAnnotationParserWithMultiComments(t *testing.T) {
 	}
 	tests.Passed("Should have successfully parsed 6 annotation markers from commentary")
 }
+
+// TestAnnotationParserWithValues validates the parsing of annotation arguments into typed values.
+func TestAnnotationParserWithValues(t *testing.T) {
+	reader := bytes.NewBufferString(`// Users of the service.
+//  @mongo(collection => "users, admins", limit => 20, ratio => 0.5, capped => true)
+//  @route(GET, /users/{id}, name => 'user\'s (view)')
+//  @index(
+//    fields => [name, "email"],
+//    options => {unique: true, sparse: false, weights: {name: 2}},
+//  )
+//  @job(name => nightly, \
+//    schedule => "0 0 * * *")
+//  @legacy(a => b, defer => true)
+//  @templater(Go, target => file.go, {
+//  func Legover() string {
+//      return "docking"
+//  }
+//  })
+`)
+
+	annotations, diagnostics := ast.ReadAnnotationsWithDiagnostics(reader)
+	if len(diagnostics) != 0 {
+		tests.Info("Diagnostics: %s", diagnostics)
+		tests.Failed("Should have parsed annotations without diagnostics")
+	}
+	tests.Passed("Should have parsed annotations without diagnostics")
+
+	if len(annotations) != 6 {
+		tests.Info("Annotations: %+v", annotations)
+		tests.Failed("Should have parsed 6 annotations")
+	}
+	tests.Passed("Should have parsed 6 annotations")
+
+	if annotations[4].String() != "@legacy(a => b, defer => true)" {
+		tests.Info("Annotation: %s", annotations[4])
+		tests.Failed("Should have printed annotation as declared")
+	}
+	tests.Passed("Should have printed annotation as declared")
+
+	mongo := annotations[0]
+	if collection, _ := mongo.StringParam("collection"); collection != "users, admins" || mongo.Param("collection") != `"users, admins"` {
+		tests.Info("Params: %+v", mongo.Params)
+		tests.Failed("Should have parsed quoted string with comma")
+	}
+	tests.Passed("Should have parsed quoted string with comma")
+
+	if limit, err := mongo.IntParam("limit"); err != nil || limit != 20 {
+		tests.Failed("Should have parsed integer param: %+q", err)
+	}
+	tests.Passed("Should have parsed integer param")
+
+	if ratio, err := mongo.FloatParam("ratio"); err != nil || ratio != 0.5 {
+		tests.Failed("Should have parsed float param: %+q", err)
+	}
+	tests.Passed("Should have parsed float param")
+
+	if capped, err := mongo.BoolParam("capped"); err != nil || !capped {
+		tests.Failed("Should have parsed boolean param: %+q", err)
+	}
+	tests.Passed("Should have parsed boolean param")
+
+	if _, err := mongo.IntParam("collection"); err == nil {
+		tests.Failed("Should have failed to read string param as integer")
+	}
+	tests.Passed("Should have failed to read string param as integer")
+
+	route := annotations[1]
+	if len(route.Arguments) != 3 || route.Arguments[1] != "/users/{id}" {
+		tests.Info("Arguments: %+q", route.Arguments)
+		tests.Failed("Should have parsed unquoted argument with braces")
+	}
+	tests.Passed("Should have parsed unquoted argument with braces")
+
+	if name, _ := route.StringParam("name"); name != "user's (view)" {
+		tests.Info("Name: %+q", name)
+		tests.Failed("Should have parsed single quoted string with escapes and parentheses")
+	}
+	tests.Passed("Should have parsed single quoted string with escapes and parentheses")
+
+	index := annotations[2]
+	fields, err := index.ListParam("fields")
+	if err != nil || len(fields) != 2 || fields[0].Text != "name" || fields[1].Text != "email" || fields[1].Kind != ast.StringValue {
+		tests.Info("Fields: %+v", fields)
+		tests.Failed("Should have parsed multi-line list param")
+	}
+	tests.Passed("Should have parsed multi-line list param")
+
+	options, err := index.MapParam("options")
+	if err != nil || len(options) != 3 {
+		tests.Info("Options: %+v", options)
+		tests.Failed("Should have parsed map param: %+q", err)
+	}
+	tests.Passed("Should have parsed map param")
+
+	if unique, err := options["unique"].Bool(); err != nil || !unique {
+		tests.Failed("Should have parsed boolean within map")
+	}
+	tests.Passed("Should have parsed boolean within map")
+
+	if weights, err := options["weights"].Map(); err != nil || weights["name"].Interface() != int64(2) {
+		tests.Info("Weights: %+v", options["weights"])
+		tests.Failed("Should have parsed nested map")
+	}
+	tests.Passed("Should have parsed nested map")
+
+	if schedule, _ := annotations[3].StringParam("schedule"); schedule != "0 0 * * *" || annotations[3].Param("name") != "nightly" {
+		tests.Info("Params: %+v", annotations[3].Params)
+		tests.Failed("Should have parsed arguments continued with backslash")
+	}
+	tests.Passed("Should have parsed arguments continued with backslash")
+
+	if legacy := annotations[4]; legacy.Param("a") != "b" || !legacy.Defer || legacy.Position.Line != 10 {
+		tests.Info("Annotation: %+v", legacy)
+		tests.Failed("Should have parsed key => value params")
+	}
+	tests.Passed("Should have parsed key => value params")
+
+	templater := annotations[5]
+	if templater.Param("target") != "file.go" || !templater.HasArg("Go") || !strings.Contains(templater.Template, "func Legover() string {") {
+		tests.Info("Annotation: %+v", templater)
+		tests.Failed("Should have parsed template annotation with arguments")
+	}
+	tests.Passed("Should have parsed template annotation with arguments")
+}
+
+// TestAnnotationParserWithInvalidValues validates the fallback to splitting arguments on commas
+// for arguments which fail to parse.
+func TestAnnotationParserWithInvalidValues(t *testing.T) {
+	reader := bytes.NewBufferString(`//  @mongo(collection => "users" admins, limit => 20)
+//  @route(GET, /users
+`)
+
+	annotations, diagnostics := ast.ReadAnnotationsWithDiagnostics(reader)
+	if len(annotations) != 2 || len(diagnostics) != 2 {
+		tests.Info("Annotations: %+v", annotations)
+		tests.Info("Diagnostics: %s", diagnostics)
+		tests.Failed("Should have parsed annotations with diagnostics")
+	}
+	tests.Passed("Should have parsed annotations with diagnostics")
+
+	if annotations[0].Param("limit") != "20" || annotations[0].Param("collection") != `"users" admins` {
+		tests.Info("Params: %+v", annotations[0].Params)
+		tests.Failed("Should have split invalid arguments on commas")
+	}
+	tests.Passed("Should have split invalid arguments on commas")
+
+	if len(annotations[1].Arguments) != 2 || annotations[1].Arguments[1] != "/users" {
+		tests.Info("Arguments: %+q", annotations[1].Arguments)
+		tests.Failed("Should have read arguments without closing parenthesis")
+	}
+	tests.Passed("Should have read arguments without closing parenthesis")
+}
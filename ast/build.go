@@ -7,10 +7,12 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,7 +22,6 @@ import (
 	"github.com/influx6/faux/types/actions"
 	"github.com/influx6/faux/types/events"
 	"github.com/influx6/gobuild/build"
-	"github.com/influx6/gobuild/srcpath"
 	"github.com/influx6/moz/gen"
 )
 
@@ -36,9 +37,10 @@ var (
 	processedPackages = struct {
 		pl         sync.Mutex
 		pkgs       map[string]Package
-		processing map[string]struct{}
+		processing map[string]chan struct{}
 	}{
-		pkgs: make(map[string]Package),
+		pkgs:       make(map[string]Package),
+		processing: make(map[string]chan struct{}),
 	}
 )
 
@@ -60,6 +62,19 @@ func ParseAnnotations(log metrics.Metrics, dir string) (Packages, error) {
 // collected context details for the package and only processes the files found by the build context.
 // If you need something more broad without filtering, use PackageWithBuildCtx.
 func FilteredPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context) (Packages, error) {
+	return filteredPackageWithBuildCtx(log, dir, ctx, false)
+}
+
+// TypedPackageWithBuildCtx parses the package directory as FilteredPackageWithBuildCtx does, but also
+// type checks the package with go/types, which makes all declarations, fields and ArgTypes carry
+// their resolved types.Object and types.Type. Imports are resolved from source, with their files
+// selected by the build.Context as the package's are. Type errors are reported to the metrics and
+// do not stop the loading of the package.
+func TypedPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context) (Packages, error) {
+	return filteredPackageWithBuildCtx(log, dir, ctx, true)
+}
+
+func filteredPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context, typed bool) (Packages, error) {
 	rootbuildPkg, err := ctx.ImportDir(dir, 0)
 	if err != nil {
 		log.Emit(metrics.Errorf("Failed to retrieve build.Package for root directory"),
@@ -100,10 +115,30 @@ func FilteredPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Cont
 	packageDeclrs := make(map[string]Package)
 	packageBuilds := make(map[string]*build.Package)
 
-	for tag, pkg := range packages {
+	// The importer is shared between all packages to reuse the imports
+	// already type checked, selecting their files with the build.Context.
+	var importer *buildImporter
+	if typed {
+		importer = newBuildImporter(ctx, tokenFiles)
+	}
+
+	for _, tag := range sortedPackages(packages) {
+		pkg := packages[tag]
+
 		var pkgFiles []string
 
-		for path, file := range pkg.Files {
+		var typesPkg *types.Package
+		var typesInfo *types.Info
+		var typesDiagnostics Diagnostics
+		if typed {
+			typesPkg, typesInfo, typesDiagnostics = checkPackage(log, dir, importer, tokenFiles, pkg)
+		}
+
+		scope := newPackageScope(ctx)
+
+		for _, path := range sortedFiles(pkg) {
+			file := pkg.Files[path]
+
 			pkgFiles = append(pkgFiles, path)
 			pathPkg := filepath.Dir(path)
 			buildPkg, ok := packageBuilds[pathPkg]
@@ -128,12 +163,18 @@ func FilteredPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Cont
 				}
 			}
 
-			res, err := parseFileToPackage(log, dir, path, pkg.Name, tokenFiles, file, pkg)
+			res, err := parseFileToPackage(log, dir, path, pkg.Name, tokenFiles, file, pkg, scope, typesPkg, typesInfo)
 			if err != nil {
 				log.Emit(metrics.Error(err), metrics.With("message", "Failed to parse file"), metrics.With("dir", dir), metrics.With("file", file.Name.Name), metrics.With("Package", pkg.Name))
 				return nil, err
 			}
 
+			for _, diag := range typesDiagnostics {
+				if diag.Position.Filename == path {
+					res.Diagnostics = append(res.Diagnostics, diag)
+				}
+			}
+
 			log.Emit(metrics.Info("Parsed Package File"), metrics.With("dir", dir), metrics.With("file", file.Name.Name), metrics.With("path", path), metrics.With("Package", pkg.Name))
 
 			if owner, ok := packageDeclrs[pkg.Name]; ok {
@@ -164,6 +205,8 @@ func FilteredPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Cont
 				Files:        pkgFiles,
 				Packages:     codePkgs,
 				TestPackages: testPkgs,
+				TypesPackage: typesPkg,
+				TypesInfo:    typesInfo,
 			}
 		}
 
@@ -173,12 +216,7 @@ func FilteredPackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Cont
 		}
 	}
 
-	var pkgs []Package
-	for _, pkg := range packageDeclrs {
-		pkgs = append(pkgs, pkg)
-	}
-
-	return pkgs, nil
+	return sortedDeclrs(packageDeclrs), nil
 }
 
 // PackageWithBuildCtx parses the package directory which generates a series of ast with associated
@@ -197,7 +235,8 @@ func PackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context) ([]
 	packageDeclrs := make(map[string]Package)
 	packageBuilds := make(map[string]*build.Package)
 
-	for pkgTag, pkg := range packages {
+	for _, pkgTag := range sortedPackages(packages) {
+		pkg := packages[pkgTag]
 		uniqueDir := fmt.Sprintf("%s#%s", dir, pkgTag)
 
 		processedPackages.pl.Lock()
@@ -212,7 +251,10 @@ func PackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context) ([]
 
 		var pkgFiles []string
 
-		for path, file := range pkg.Files {
+		scope := newPackageScope(ctx)
+		for _, path := range sortedFiles(pkg) {
+			file := pkg.Files[path]
+
 			pkgFiles = append(pkgFiles, path)
 
 			pathPkg := filepath.Dir(path)
@@ -237,7 +279,7 @@ func PackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context) ([]
 				}
 			}
 
-			res, err := parseFileToPackage(log, dir, path, pkg.Name, tokenFiles, file, pkg)
+			res, err := parseFileToPackage(log, dir, path, pkg.Name, tokenFiles, file, pkg, scope, nil, nil)
 			if err != nil {
 				log.Emit(metrics.Error(err), metrics.With("message", "Failed to parse file"), metrics.With("dir", dir), metrics.With("file", file.Name.Name), metrics.With("Package", pkg.Name))
 				return nil, err
@@ -286,12 +328,48 @@ func PackageWithBuildCtx(log metrics.Metrics, dir string, ctx build.Context) ([]
 		}
 	}
 
-	var pkgs []Package
+	return sortedDeclrs(packageDeclrs), nil
+}
+
+// sortedPackages returns the names of the parsed packages in sorted order, as the order of
+// declarations and the directives generated for them must not depend on map iteration.
+func sortedPackages(packages map[string]*ast.Package) []string {
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// sortedFiles returns the paths of the files of the package in sorted order.
+func sortedFiles(pkg *ast.Package) []string {
+	paths := make([]string, 0, len(pkg.Files))
+	for path := range pkg.Files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// sortedDeclrs returns the packages ordered by their import path and name.
+func sortedDeclrs(packageDeclrs map[string]Package) []Package {
+	pkgs := make([]Package, 0, len(packageDeclrs))
 	for _, pkg := range packageDeclrs {
 		pkgs = append(pkgs, pkg)
 	}
 
-	return pkgs, nil
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Path != pkgs[j].Path {
+			return pkgs[i].Path < pkgs[j].Path
+		}
+
+		return pkgs[i].Name < pkgs[j].Name
+	})
+
+	return pkgs
 }
 
 // PackageFileWithBuildCtx parses the package from the provided file.
@@ -350,7 +428,7 @@ func PackageFileWithBuildCtx(log metrics.Metrics, path string, ctx build.Context
 
 		pkgFiles = append(pkgFiles, fpath)
 
-		res, err := parseFileToPackage(log, dir, path, buildPkg.Name, tokenFiles, file, pkg)
+		res, err := parseFileToPackage(log, dir, path, buildPkg.Name, tokenFiles, file, pkg, newPackageScope(ctx), nil, nil)
 		if err != nil {
 			log.Emit(metrics.Error(err), metrics.With("message", "Failed to parse file"), metrics.With("dir", dir), metrics.With("file", file.Name.Name), metrics.With("Package", pkg.Name))
 			return Package{}, err
@@ -378,13 +456,63 @@ func PackageFileWithBuildCtx(log metrics.Metrics, path string, ctx build.Context
 	return Package{}, ErrPackageParseFailed
 }
 
-func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName string, tokenFiles *token.FileSet, file *ast.File, pkgAstObj *ast.Package) (PackageDeclaration, error) {
+// checkPackage type checks the files of the package, returning the types.Package and types.Info
+// of the package, which are incomplete when the package has type errors, and the type errors
+// as diagnostics.
+func checkPackage(log metrics.Metrics, dir string, importer *buildImporter, tokenFiles *token.FileSet, pkg *ast.Package) (*types.Package, *types.Info, Diagnostics) {
+	importPath, err := ImportPathFor(dir)
+	if err != nil {
+		importPath = pkg.Name
+	}
+
+	paths := sortedFiles(pkg)
+
+	files := make([]*ast.File, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, pkg.Files[path])
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*ast.Ident]types.Instance),
+	}
+
+	var diagnostics Diagnostics
+
+	config := types.Config{
+		Importer:    importer,
+		Sizes:       importer.sizes,
+		FakeImportC: true,
+		Error: func(err error) {
+			diagnostics = append(diagnostics, typeErrorDiagnostic(err))
+			log.Emit(metrics.Error(err), metrics.With("message", "Type checking failed"), metrics.With("dir", dir), metrics.With("Package", pkg.Name))
+		},
+	}
+
+	typesPkg, _ := config.Check(importPath, tokenFiles, files, info)
+	return typesPkg, info, diagnostics
+}
+
+func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName string, tokenFiles *token.FileSet, file *ast.File, pkgAstObj *ast.Package, scope *packageScope, typesPkg *types.Package, typesInfo *types.Info) (PackageDeclaration, error) {
 	var packageDeclr PackageDeclaration
 
+	// Declarations of the file point to packageDeclr, which is the declaration other files
+	// of the package find the file's by.
+	scope.declrs[path] = &packageDeclr
+
 	{
 		pkgSource, _ := readSource(path)
 
 		packageDeclr.Package = pkgName
+		packageDeclr.TypesPackage = typesPkg
+		packageDeclr.TypesInfo = typesInfo
+		packageDeclr.astPkg = pkgAstObj
+		packageDeclr.scope = scope
+		packageDeclr.tokenFiles = tokenFiles
 		packageDeclr.Dir = dir
 		packageDeclr.FilePath = path
 		packageDeclr.Source = string(pkgSource)
@@ -442,16 +570,13 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 				comment = imp.Comment.Text()
 			}
 
-			var internal bool
-			if _, err := relativeToSrc(filepath.Join(goSrcPath, impPkgPath)); err != nil {
-				internal = true
-			}
+			impDir, found := ImportDirFor(dir, impPkgPath)
 
 			imported := ImportDeclaration{
 				Comments:    comment,
 				Name:        pkgName,
 				Path:        impPkgPath,
-				InternalPkg: internal,
+				InternalPkg: !found,
 				Source:      string(source),
 			}
 
@@ -461,9 +586,7 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 				var importDir string
 
 				if !imported.InternalPkg {
-					importDir = srcpath.FromSrcPath(imported.Path)
-				} else {
-					//importDir = srcpath.FromRootPath(imported.Path)
+					importDir = impDir
 				}
 
 				// Check if import path exists else skip.
@@ -500,7 +623,8 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 		}
 
 		if file.Doc != nil {
-			annotationRead := ReadAnnotationsFromCommentry(bytes.NewBufferString(file.Doc.Text()))
+			annotationRead, diagnostics := ReadAnnotationsFromComments(tokenFiles, file.Doc)
+			packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, diagnostics...)
 
 			log.Emit(metrics.Info("Annotations in Package comments"),
 				metrics.With("dir", dir),
@@ -510,6 +634,8 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 			packageDeclr.Annotations = append(packageDeclr.Annotations, annotationRead...)
 		}
 
+		unresolvedReceivers := make(map[string]*ast.Object)
+
 		// Collect and categorize annotations in types and their fields.
 	declrLoop:
 		for _, declr := range file.Decls {
@@ -536,34 +662,16 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 				associations := make(map[string]AnnotationAssociationDeclaration, 0)
 
 				if rdeclr.Doc != nil {
-					annotationRead := ReadAnnotationsFromCommentry(bytes.NewBufferString(rdeclr.Doc.Text()))
+					annotationRead, diagnostics := ReadAnnotationsFromComments(tokenFiles, rdeclr.Doc)
+					packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, diagnostics...)
 
 					for _, item := range annotationRead {
 						log.Emit(metrics.Info("Annotation in Function Decleration comment"), metrics.With("dir", dir), metrics.With("annotation", item.Name))
-
-						switch item.Name {
-						case "associates":
-							log.Emit(
-								metrics.Info("Association found"),
-								metrics.With("dir", dir),
-								metrics.With("association", item.Arguments),
-							)
-
-							if len(item.Arguments) >= 3 {
-								associations[item.Arguments[0]] = AnnotationAssociationDeclaration{
-									Record:     item,
-									Template:   item.Template,
-									Action:     item.Arguments[1],
-									TypeName:   item.Arguments[2],
-									Annotation: strings.TrimPrefix(item.Arguments[0], "@"),
-								}
-							} else {
-								log.Emit(metrics.Error(errors.New("Association Annotation in Declaration is incomplete: Expects 3 elements")), metrics.With("dir", dir), metrics.With("association", item.Arguments))
-							}
-						default:
-							annotations = append(annotations, item)
-						}
 					}
+
+					var invalid Diagnostics
+					annotations, associations, invalid = readAssociations(log, dir, annotationRead)
+					packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, invalid...)
 				}
 
 				var defFunc FuncDeclaration
@@ -574,6 +682,7 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 				defFunc.FuncDeclr = rdeclr
 				defFunc.Type = rdeclr.Type
 				defFunc.Position = rdeclr.Pos()
+				defFunc.Location = beginPosition
 				defFunc.Path = packageDeclr.Path
 				defFunc.File = packageDeclr.File
 				defFunc.Declr = &packageDeclr
@@ -586,35 +695,62 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 				defFunc.Annotations = annotations
 				defFunc.Associations = associations
 				defFunc.Exported = unicode.IsUpper(rune(rdeclr.Name.Name[0]))
+				defFunc.TypesObject, defFunc.TypesType = packageDeclr.objectOf(rdeclr.Name)
 
 				if rdeclr.Type != nil {
 					defFunc.Returns = rdeclr.Type.Results
 					defFunc.Arguments = rdeclr.Type.Params
+					defFunc.TypeParams = GetTypeParams(rdeclr.Type.TypeParams)
 				}
 
 				if rdeclr.Recv != nil {
 					defFunc.FuncType = rdeclr.Recv
 
 					nameIdent := rdeclr.Recv.List[0]
-					var receiverNameType *ast.Ident
+					receiverType := nameIdent.Type
 
-					switch nmi := nameIdent.Type.(type) {
-					case *ast.Ident:
-						receiverNameType = nmi
-					case *ast.StarExpr:
-						receiverNameType = nmi.X.(*ast.Ident)
+					if nmi, ok := receiverType.(*ast.StarExpr); ok {
+						receiverType = nmi.X
 						defFunc.RecieverPointer = nmi
 					}
 
-					defFunc.Reciever = receiverNameType.Obj
+					// Methods of generic types declare the type parameters of the receiver,
+					// e.g `func (l *List[T]) Push(v T)`.
+					receiverType, receiverParams := genericIndices(receiverType)
+					for _, param := range receiverParams {
+						defFunc.RecieverTypeParams = append(defFunc.RecieverTypeParams, getName(param))
+					}
+
+					receiverNameType, ok := receiverType.(*ast.Ident)
+					if !ok {
+						log.Emit(metrics.Error(errors.New("Unknown method receiver type")), metrics.With("dir", dir), metrics.With("method", rdeclr.Name.Name))
+						packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, Diagnostic{
+							Severity: SeverityWarning,
+							Position: tokenFiles.Position(rdeclr.Recv.Pos()),
+							Message:  fmt.Sprintf("unknown receiver type of method %s", rdeclr.Name.Name),
+						})
+						continue declrLoop
+					}
+
+					// Receivers of types declared in another file of the package are not resolved
+					// by the parser, methods of such receivers share a object by name.
+					receiverObj := receiverNameType.Obj
+					if receiverObj == nil {
+						if receiverObj, ok = unresolvedReceivers[receiverNameType.Name]; !ok {
+							receiverObj = ast.NewObj(ast.Typ, receiverNameType.Name)
+							unresolvedReceivers[receiverNameType.Name] = receiverObj
+						}
+					}
+
+					defFunc.Reciever = receiverObj
 					defFunc.RecieverIdent = receiverNameType
 					defFunc.RecieverName = receiverNameType.Name
 
-					if rems, ok := packageDeclr.ObjectFunc[receiverNameType.Obj]; ok {
+					if rems, ok := packageDeclr.ObjectFunc[receiverObj]; ok {
 						rems = append(rems, defFunc)
-						packageDeclr.ObjectFunc[receiverNameType.Obj] = rems
+						packageDeclr.ObjectFunc[receiverObj] = rems
 					} else {
-						packageDeclr.ObjectFunc[receiverNameType.Obj] = []FuncDeclaration{defFunc}
+						packageDeclr.ObjectFunc[receiverObj] = []FuncDeclaration{defFunc}
 					}
 
 					continue declrLoop
@@ -635,36 +771,18 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 				associations := make(map[string]AnnotationAssociationDeclaration, 0)
 
 				if rdeclr.Doc != nil {
-					annotationRead := ReadAnnotationsFromCommentry(bytes.NewBufferString(rdeclr.Doc.Text()))
+					annotationRead, diagnostics := ReadAnnotationsFromComments(tokenFiles, rdeclr.Doc)
+					packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, diagnostics...)
 
 					for _, item := range annotationRead {
 						log.Emit(metrics.Info("Annotation in Decleration comment"),
 							metrics.With("dir", dir),
 							metrics.With("annotation", item.Name))
-
-						switch item.Name {
-						case "associates":
-							log.Emit(
-								metrics.Info("Association found"),
-								metrics.With("dir", dir),
-								metrics.With("association", item.Arguments),
-							)
-
-							if len(item.Arguments) >= 3 {
-								associations[item.Arguments[0]] = AnnotationAssociationDeclaration{
-									Record:     item,
-									Template:   item.Template,
-									Action:     item.Arguments[1],
-									TypeName:   item.Arguments[2],
-									Annotation: strings.TrimPrefix(item.Arguments[0], "@"),
-								}
-							} else {
-								log.Emit(metrics.Error(errors.New("Association Annotation in Declaration is incomplete: Expects 3 elements")), metrics.With("dir", dir), metrics.With("association", item.Arguments))
-							}
-						default:
-							annotations = append(annotations, item)
-						}
 					}
+
+					var invalid Diagnostics
+					annotations, associations, invalid = readAssociations(log, dir, annotationRead)
+					packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, invalid...)
 				}
 
 				for _, spec := range rdeclr.Specs {
@@ -685,16 +803,33 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 							nameWithPackage = fmt.Sprintf("%s.%s", packageDeclr.Package, name)
 						}
 
+						// Specs within a grouped declaration can carry their own annotations,
+						// which are added to those of the group.
+						specAnnotations, specComment := annotations, comment
+						if obj.Doc != nil && rdeclr.Lparen.IsValid() {
+							specRead, diagnostics := ReadAnnotationsFromComments(tokenFiles, obj.Doc)
+							packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, diagnostics...)
+
+							specComment = obj.Doc.Text()
+							specAnnotations = append(specAnnotations[:len(specAnnotations):len(specAnnotations)], specRead...)
+						}
+
+						typesObject, typesType := packageDeclr.objectOf(nameIdent)
+
 						packageDeclr.Variables = append(packageDeclr.Variables, VariableDeclaration{
 							Object:          obj,
+							TypesObject:     typesObject,
+							TypesType:       typesType,
 							Name:            name,
 							NameIdent:       nameIdent,
 							NameWithPackage: nameWithPackage,
-							Annotations:     annotations,
+							Annotations:     specAnnotations,
 							Associations:    associations,
 							GenObj:          rdeclr,
+							Position:        obj.Pos(),
+							Location:        tokenFiles.Position(obj.Pos()),
 							Source:          string(source),
-							Comments:        comment,
+							Comments:        specComment,
 							Declr:           &packageDeclr,
 							File:            packageDeclr.File,
 							Package:         packageDeclr.Package,
@@ -705,6 +840,7 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 						})
 
 					case *ast.TypeSpec:
+						typesObject, typesType := packageDeclr.objectOf(obj.Name)
 
 						switch robj := obj.Type.(type) {
 						case *ast.StructType:
@@ -714,14 +850,21 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 								metrics.With("Annotations", len(annotations)),
 								metrics.With("StructName", obj.Name.Name))
 
+							packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, fieldListDiagnostics(tokenFiles, robj.Fields)...)
+
 							packageDeclr.Structs = append(packageDeclr.Structs, StructDeclaration{
 								Object:          obj,
 								Struct:          robj,
+								TypesObject:     typesObject,
+								TypesType:       typesType,
+								TypeParams:      GetTypeParams(obj.TypeParams),
 								Name:            obj.Name.Name,
 								NameWithPackage: fmt.Sprintf("%s.%s", packageDeclr.Package, obj.Name.Name),
 								Annotations:     annotations,
 								Associations:    associations,
 								GenObj:          rdeclr,
+								Position:        obj.Pos(),
+								Location:        tokenFiles.Position(obj.Pos()),
 								Source:          string(source),
 								Comments:        comment,
 								Declr:           &packageDeclr,
@@ -739,10 +882,26 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 								metrics.With("Annotations", len(annotations)),
 								metrics.With("StructName", obj.Name.Name))
 
+							packageDeclr.Diagnostics = append(packageDeclr.Diagnostics, fieldListDiagnostics(tokenFiles, robj.Methods)...)
+
+							var constraints []string
+							for _, method := range robj.Methods.List {
+								switch method.Type.(type) {
+								case *ast.BinaryExpr, *ast.UnaryExpr:
+									constraints = append(constraints, types.ExprString(method.Type))
+								}
+							}
+
 							packageDeclr.Interfaces = append(packageDeclr.Interfaces, InterfaceDeclaration{
 								Object:          obj,
 								Interface:       robj,
+								TypesObject:     typesObject,
+								TypesType:       typesType,
+								TypeParams:      GetTypeParams(obj.TypeParams),
+								Constraints:     constraints,
 								GenObj:          rdeclr,
+								Position:        obj.Pos(),
+								Location:        tokenFiles.Position(obj.Pos()),
 								Name:            obj.Name.Name,
 								NameWithPackage: fmt.Sprintf("%s.%s", packageDeclr.Package, obj.Name.Name),
 								Comments:        comment,
@@ -805,14 +964,35 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 								}
 							}
 
+							// Instantiations of generic types, e.g `type Names List[string]`.
+							switch obj.Type.(type) {
+							case *ast.IndexExpr, *ast.IndexListExpr:
+								var field ast.Field
+								field.Type = obj.Type
+								if arg, err := GetArgTypeFromField(1, "type", obj.Name.Name, packageDeclr.File, &field, &packageDeclr); err == nil {
+									argType = &arg
+								} else {
+									log.Emit(
+										metrics.Error(err),
+										metrics.Message("Failed to parse generic type instantiation"),
+										metrics.With("type", obj.Name.Name),
+									)
+								}
+							}
+
 							packageDeclr.Types = append(packageDeclr.Types, TypeDeclaration{
 								Object:          obj,
 								GenObj:          rdeclr,
+								Position:        obj.Pos(),
+								Location:        tokenFiles.Position(obj.Pos()),
+								TypesObject:     typesObject,
+								TypesType:       typesType,
 								Annotations:     annotations,
 								Name:            obj.Name.Name,
 								NameWithPackage: fmt.Sprintf("%s.%s", packageDeclr.Package, obj.Name.Name),
 								Comments:        comment,
 								TypeInfo:        argType,
+								TypeParams:      GetTypeParams(obj.TypeParams),
 								Type:            mainType,
 								AliasedType:     aliasedObject,
 								AliasedTypeSpec: aliasedObjectSpec,
@@ -844,40 +1024,137 @@ func parseFileToPackage(log metrics.Metrics, dir string, path string, pkgName st
 	return packageDeclr, nil
 }
 
+// relativeToSrc returns the import path of the directory of the giving file joined
+// with the file's name, resolving from the go module or GOPATH containing it.
 func relativeToSrc(path string) (string, error) {
 	if _, err := os.Stat(path); err != nil {
 		return "", err
 	}
 
-	return filepath.Rel(goSrcPath, path)
+	dirPath, err := ImportPathFor(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dirPath, filepath.Base(path)), nil
+}
+
+// directiveWriter returns the writer of the directive, formatting it's content with gen.FormatSource
+// if the directive requests formatting of a .go file. Invalid Go source is reported with the
+// file name and line of the error.
+func directiveWriter(namedFile string, item gen.WriteDirective) (io.WriterTo, error) {
+	if !item.Format || filepath.Ext(item.FileName) != ".go" {
+		return item.Writer, nil
+	}
+
+	var src bytes.Buffer
+	if _, err := item.Writer.WriteTo(&src); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("IOError: Unable to write content to file: %+q", err)
+	}
+
+	formatted, err := gen.FormatSource(namedFile, src.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return gen.NewConstantWriter(formatted), nil
 }
 
 //===========================================================================================================
 
 // SimplyParse takes the provided packages parsing all internals declarations with the appropriate generators suited to the type and annotations.
-// Relies on SimpleParsePackage.
+// Deferred annotations of all packages are run after the others (see AnnotationRegistry.ParsePackages).
 func SimplyParse(toDir string, log metrics.Metrics, provider *AnnotationRegistry, doFileOverwrite bool, pkgDeclrs ...Package) error {
-	for _, pkg := range pkgDeclrs {
-		if err := SimplyParsePackage(toDir, log, provider, doFileOverwrite, pkg); err != nil {
+	wdrs, err := parseDirectives(toDir, log, provider, ParseConfig{}, pkgDeclrs)
+	if err != nil {
+		return err
+	}
+
+	for _, wd := range wdrs {
+		if err := SimpleWriteDirective(toDir, doFileOverwrite, wd.WriteDirective); err != nil {
+			log.Emit(metrics.Error(err), metrics.With("annotation", wd.Annotation),
+				metrics.With("dir", toDir),
+				metrics.With("file", wd.Source))
 			return err
 		}
+
+		log.Emit(metrics.Info("Annotation Resolved"), metrics.With("annotation", wd.Annotation),
+			metrics.With("dir", toDir),
+			metrics.With("file", wd.Source))
 	}
 
 	return nil
 }
 
 // Parse takes the provided packages parsing all internals declarations with the appropriate generators suited to the type and annotations.
-// Relies on ParsePackage.
+// Deferred annotations of all packages are run after the others (see AnnotationRegistry.ParsePackages).
 func Parse(toDir string, log metrics.Metrics, provider *AnnotationRegistry, doFileOverwrite bool, pkgDeclrs ...Package) error {
-	for _, pkg := range pkgDeclrs {
-		if err := ParsePackage(toDir, log, provider, doFileOverwrite, pkg); err != nil {
+	return ParseWith(toDir, log, provider, doFileOverwrite, ParseConfig{}, pkgDeclrs...)
+}
+
+// ParseWith parses the packages as done by Parse, running the generators with the config (see
+// AnnotationRegistry.ParsePackagesWith). When keeping going, the directives of successful generators
+// are written before the GenerationErrors of the others are returned, none are if config.AllOrNothing.
+func ParseWith(toDir string, log metrics.Metrics, provider *AnnotationRegistry, doFileOverwrite bool, config ParseConfig, pkgDeclrs ...Package) error {
+	wdrs, err := parseDirectives(toDir, log, provider, config, pkgDeclrs)
+	failures, keepGoing := err.(GenerationErrors)
+	if err != nil && !keepGoing {
+		return err
+	}
+
+	for _, wd := range wdrs {
+		if err := WriteDirective(log, toDir, doFileOverwrite, wd.WriteDirective); err != nil {
+			log.Emit(metrics.Error(err), metrics.With("annotation", wd.Annotation),
+				metrics.With("dir", toDir),
+				metrics.With("file", wd.Source))
 			return err
 		}
+
+		log.Emit(metrics.Info("Annotation Resolved"), metrics.With("annotation", wd.Annotation),
+			metrics.With("dir", toDir),
+			metrics.With("file", wd.Source))
+	}
+
+	if keepGoing {
+		return failures
 	}
 
 	return nil
 }
 
+// parseDirectives returns the directives of the generators of all packages for the destination,
+// run with the config. When keeping going, the directives of successful generators are returned
+// with the GenerationErrors of the others.
+// Provided toDir must be a absolute path.
+func parseDirectives(toDir string, log metrics.Metrics, provider *AnnotationRegistry, config ParseConfig, pkgDeclrs Packages) ([]AnnotationWriteDirective, error) {
+	log.Emit(metrics.Info("Begin ParsePackage"), metrics.With("toDir", toDir),
+		metrics.With("packages", len(pkgDeclrs)))
+
+	if !filepath.IsAbs(toDir) {
+		return nil, errors.New("Destination path must be a absolute path directory")
+	}
+
+	toSrcPath, err := ImportPathFor(toDir)
+	if err != nil {
+		return nil, fmt.Errorf("Destination path is not within a go module or current GOPATH: %+q", err.Error())
+	}
+
+	if config.Dir == "" {
+		config.Dir = toDir
+	}
+
+	wdrs, err := provider.ParsePackagesWith(config, pkgDeclrs, toSrcPath)
+	if err != nil {
+		log.Emit(metrics.Error(errors.New("ParseFailure")),
+			metrics.With("error", err.Error()), metrics.With("toDir", toDir))
+		return wdrs, err
+	}
+
+	log.Emit(metrics.Info("ParseSuccess"), metrics.With("toDir", toDir), metrics.With("Directives", len(wdrs)))
+
+	return wdrs, nil
+}
+
 // WriteDirectives defines a function which houses the logic to write WriteDirective into file system.
 func WriteDirectives(log metrics.Metrics, toDir string, doFileOverwrite bool, wds ...gen.WriteDirective) error {
 	for _, wd := range wds {
@@ -919,6 +1196,10 @@ func SimpleWriteDirective(toDir string, doFileOverwrite bool, item gen.WriteDire
 		namedFileDir = filepath.Join(toDir, item.Dir)
 	}
 
+	if !withinDir(toDir, filepath.Join(namedFileDir, item.FileName)) {
+		return fmt.Errorf("gen.WriteDirectiveError: Expected path within %+q: %+q", toDir, filepath.Join(item.Dir, item.FileName))
+	}
+
 	if filepath.IsAbs(baseDir) {
 		baseDir = filepath.Base(baseDir)
 	}
@@ -951,6 +1232,11 @@ func SimpleWriteDirective(toDir string, doFileOverwrite bool, item gen.WriteDire
 		return err
 	}
 
+	writer, err := directiveWriter(namedFile, item)
+	if err != nil {
+		return err
+	}
+
 	newFile, err := os.Create(namedFile)
 	if err != nil {
 		return err
@@ -960,7 +1246,7 @@ func SimpleWriteDirective(toDir string, doFileOverwrite bool, item gen.WriteDire
 
 	defer newFile.Close()
 
-	_, err = item.Writer.WriteTo(newFile)
+	_, err = writer.WriteTo(newFile)
 	if err != nil && err != io.EOF {
 		err = fmt.Errorf("IOError: Unable to write content to file: %+q", err)
 		return err
@@ -973,6 +1259,16 @@ func SimpleWriteDirective(toDir string, doFileOverwrite bool, item gen.WriteDire
 	return item.After()
 }
 
+// withinDir returns true/false if the path is the directory or within it, once both are cleaned.
+func withinDir(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // WriteDirective defines a function which houses the logic to write WriteDirective into file system.
 func WriteDirective(log metrics.Metrics, toDir string, doFileOverwrite bool, item gen.WriteDirective) error {
 	if item.Before != nil {
@@ -1000,6 +1296,12 @@ func WriteDirective(log metrics.Metrics, toDir string, doFileOverwrite bool, ite
 		namedFileDir = filepath.Join(toDir, item.Dir)
 	}
 
+	if !withinDir(toDir, filepath.Join(namedFileDir, item.FileName)) {
+		err := fmt.Errorf("gen.WriteDirectiveError: Expected path within %+q: %+q", toDir, filepath.Join(item.Dir, item.FileName))
+		log.Emit(metrics.Error(err), metrics.With("File", item.FileName), metrics.With("Overwrite", item.DontOverride), metrics.With("Dir", item.Dir))
+		return err
+	}
+
 	if namedFileDir != "" {
 		if err := os.MkdirAll(namedFileDir, 0700); err != nil && err != os.ErrExist {
 			err = fmt.Errorf("IOError: Unable to create directory: %+q", err)
@@ -1050,6 +1352,14 @@ func WriteDirective(log metrics.Metrics, toDir string, doFileOverwrite bool, ite
 		return err
 	}
 
+	writer, err := directiveWriter(namedFile, item)
+	if err != nil {
+		log.Emit(metrics.Error(err), metrics.With("File", item.FileName), metrics.With("Overwrite", item.DontOverride), metrics.With("Dir", item.Dir),
+			metrics.With("DestinationDir", namedFileDir),
+			metrics.With("DestinationFile", namedFile))
+		return err
+	}
+
 	newFile, err := os.Create(namedFile)
 	if err != nil {
 		log.Emit(metrics.Error(err), metrics.With("File", item.FileName), metrics.With("Overwrite", item.DontOverride), metrics.With("Dir", item.Dir),
@@ -1060,7 +1370,7 @@ func WriteDirective(log metrics.Metrics, toDir string, doFileOverwrite bool, ite
 
 	defer newFile.Close()
 
-	written, err := item.Writer.WriteTo(newFile)
+	written, err := writer.WriteTo(newFile)
 	if err != nil && err != io.EOF {
 		err = fmt.Errorf("IOError: Unable to write content to file: %+q", err)
 		log.Emit(metrics.Error(err), metrics.With("File", item.FileName), metrics.With("Overwrite", item.DontOverride), metrics.With("Dir", item.Dir),
@@ -1091,103 +1401,13 @@ func WriteDirective(log metrics.Metrics, toDir string, doFileOverwrite bool, ite
 // ParsePackage takes the provided package declrations parsing all internals with the appropriate generators suited to the type and annotations.
 // Provided toDir must be a absolute path.
 func ParsePackage(toDir string, log metrics.Metrics, provider *AnnotationRegistry, doFileOverwrite bool, pkgDeclrs Package) error {
-	log.Emit(metrics.Info("Begin ParsePackage"), metrics.With("toDir", toDir),
-		metrics.With("overwriter-file", doFileOverwrite),
-		metrics.With("package", pkgDeclrs.Path))
-
-	if !filepath.IsAbs(toDir) {
-		return errors.New("Destination path must be a absolute path directory")
-	}
-
-	toSrcPath, err := srcpath.RelativeToSrc(toDir)
-	if err != nil {
-		return fmt.Errorf("Destination path is not within current GOPATH: %+q", err.Error())
-	}
-
-	for _, pkg := range pkgDeclrs.Packages {
-		log.Emit(metrics.Info("ParsePackage: Parse PackageDeclaration"),
-			metrics.With("toDir", toDir), metrics.With("overwriter-file", doFileOverwrite),
-			metrics.With("package", pkg.Package),
-			metrics.With("From", pkg.FilePath))
-
-		wdrs, err := provider.ParseDeclr(pkgDeclrs, pkg, toSrcPath)
-		if err != nil {
-			log.Emit(metrics.Error(fmt.Errorf("ParseFailure: Package %q", pkg.Package)),
-				metrics.With("error", err.Error()), metrics.With("package", pkg.Package))
-			return err
-		}
-
-		log.Emit(metrics.Info("ParseSuccess"), metrics.With("From", pkg.FilePath), metrics.With("package", pkg.Package), metrics.With("Directives", len(wdrs)))
-
-		for _, wd := range wdrs {
-			if err := WriteDirective(log, toDir, doFileOverwrite, wd.WriteDirective); err != nil {
-				log.Emit(metrics.Error(err), metrics.With("annotation", wd.Annotation),
-					metrics.With("dir", toDir),
-					metrics.With("package", pkg.Package),
-					metrics.With("file", pkg.File))
-				return err
-			}
-
-			log.Emit(metrics.Info("Annotation Resolved"), metrics.With("annotation", wd.Annotation),
-				metrics.With("dir", toDir),
-				metrics.With("package", pkg.Package),
-				metrics.With("file", pkg.File))
-		}
-
-	}
-
-	return nil
+	return Parse(toDir, log, provider, doFileOverwrite, pkgDeclrs)
 }
 
 // SimplyParsePackage takes the provided package declrations parsing all internals with the appropriate generators suited to the type and annotations.
 // Provided toDir must be a absolute path.
 func SimplyParsePackage(toDir string, log metrics.Metrics, provider *AnnotationRegistry, doFileOverwrite bool, pkgDeclrs Package) error {
-	log.Emit(metrics.Info("Begin ParsePackage"), metrics.With("toDir", toDir),
-		metrics.With("overwriter-file", doFileOverwrite),
-		metrics.With("package", pkgDeclrs.Path))
-
-	if !filepath.IsAbs(toDir) {
-		return errors.New("Destination path must be a absolute path directory")
-	}
-
-	toSrcPath, err := srcpath.RelativeToSrc(toDir)
-	if err != nil {
-		return fmt.Errorf("Destination path is not within current GOPATH: %+q", err.Error())
-	}
-
-	for _, pkg := range pkgDeclrs.Packages {
-		log.Emit(metrics.Info("ParsePackage: Parse PackageDeclaration"),
-			metrics.With("toDir", toDir), metrics.With("overwriter-file", doFileOverwrite),
-			metrics.With("package", pkg.Package),
-			metrics.With("From", pkg.FilePath))
-
-		wdrs, err := provider.ParseDeclr(pkgDeclrs, pkg, toSrcPath)
-		if err != nil {
-			log.Emit(metrics.Error(fmt.Errorf("ParseFailure: Package %q", pkg.Package)),
-				metrics.With("error", err.Error()), metrics.With("package", pkg.Package))
-			return err
-		}
-
-		log.Emit(metrics.Info("ParseSuccess"), metrics.With("From", pkg.FilePath), metrics.With("package", pkg.Package), metrics.With("Directives", len(wdrs)))
-
-		for _, wd := range wdrs {
-			if err := SimpleWriteDirective(toDir, doFileOverwrite, wd.WriteDirective); err != nil {
-				log.Emit(metrics.Error(err), metrics.With("annotation", wd.Annotation),
-					metrics.With("dir", toDir),
-					metrics.With("package", pkg.Package),
-					metrics.With("file", pkg.File))
-				return err
-			}
-
-			log.Emit(metrics.Info("Annotation Resolved"), metrics.With("annotation", wd.Annotation),
-				metrics.With("dir", toDir),
-				metrics.With("package", pkg.Package),
-				metrics.With("file", pkg.File))
-		}
-
-	}
-
-	return nil
+	return SimplyParse(toDir, log, provider, doFileOverwrite, pkgDeclrs)
 }
 
 //===========================================================================================================
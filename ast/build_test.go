@@ -0,0 +1,42 @@
+package ast_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+// TestWriteDirectiveOutsideDir validates directives are not written outside of the destination.
+func TestWriteDirectiveOutsideDir(t *testing.T) {
+	root := t.TempDir()
+	toDir := filepath.Join(root, "out")
+
+	for _, directive := range []gen.WriteDirective{
+		{Dir: "..", FileName: "escaped.go", Writer: gen.Text("package escaped\n")},
+		{Dir: "sub", FileName: filepath.Join("..", "..", "escaped.go"), Writer: gen.Text("package escaped\n")},
+	} {
+		if err := ast.WriteDirective(metrics.New(), toDir, true, directive); err == nil {
+			tests.Failed("Should have failed to write directive outside of destination: %+v", directive)
+		}
+
+		if err := ast.SimpleWriteDirective(toDir, true, directive); err == nil {
+			tests.Failed("Should have failed to simply write directive outside of destination: %+v", directive)
+		}
+	}
+	tests.Passed("Should have failed to write directives outside of destination")
+
+	if _, err := os.Stat(filepath.Join(root, "escaped.go")); err == nil {
+		tests.Failed("Should have not written file outside of destination")
+	}
+	tests.Passed("Should have not written file outside of destination")
+
+	if err := ast.WriteDirective(metrics.New(), toDir, true, gen.WriteDirective{Dir: "sub", FileName: "inside.go", Writer: gen.Text("package sub\n")}); err != nil {
+		tests.Failed("Should have written directive within destination: %+q", err)
+	}
+	tests.Passed("Should have written directive within destination")
+}
@@ -0,0 +1,242 @@
+package ast
+
+import (
+	"errors"
+	"go/ast"
+	"go/types"
+)
+
+// ErrNotTypeChecked defines a error returned when type information is requested from a
+// declaration which was not loaded with TypedPackageWithBuildCtx.
+var ErrNotTypeChecked = errors.New("Declaration was not loaded with type checking")
+
+// ArgKind defines the kind of the underlying type of a ArgType.
+type ArgKind int
+
+// Contains the different kinds of underlying types.
+const (
+	UnknownKind ArgKind = iota
+	BasicKind
+	StructKind
+	InterfaceKind
+	MapKind
+	ChanKind
+	SliceKind
+	ArrayKind
+	PointerKind
+	FuncKind
+	TypeParamKind
+)
+
+var argKindNames = map[ArgKind]string{
+	UnknownKind:   "unknown",
+	BasicKind:     "basic",
+	StructKind:    "struct",
+	InterfaceKind: "interface",
+	MapKind:       "map",
+	ChanKind:      "chan",
+	SliceKind:     "slice",
+	ArrayKind:     "array",
+	PointerKind:   "pointer",
+	FuncKind:      "func",
+	TypeParamKind: "typeparam",
+}
+
+// String returns the name of the kind.
+func (k ArgKind) String() string {
+	if name, ok := argKindNames[k]; ok {
+		return name
+	}
+
+	return argKindNames[UnknownKind]
+}
+
+// KindOf returns the ArgKind of the underlying type of the giving types.Type.
+func KindOf(typ types.Type) ArgKind {
+	if typ == nil {
+		return UnknownKind
+	}
+
+	if _, ok := types.Unalias(typ).(*types.TypeParam); ok {
+		return TypeParamKind
+	}
+
+	switch typ.Underlying().(type) {
+	case *types.Basic:
+		return BasicKind
+	case *types.Struct:
+		return StructKind
+	case *types.Interface:
+		return InterfaceKind
+	case *types.Map:
+		return MapKind
+	case *types.Chan:
+		return ChanKind
+	case *types.Slice:
+		return SliceKind
+	case *types.Array:
+		return ArrayKind
+	case *types.Pointer:
+		return PointerKind
+	case *types.Signature:
+		return FuncKind
+	default:
+		return UnknownKind
+	}
+}
+
+// Kind returns the kind of the underlying type of the argument. The resolved type is used
+// when loaded with type checking, else the kind is derived from the argument's syntax, which
+// can not see through named types declared in other packages.
+func (a ArgType) Kind() ArgKind {
+	if a.TypesType != nil {
+		return KindOf(a.TypesType)
+	}
+
+	switch {
+	case a.TypeParam:
+		return TypeParamKind
+	case a.PointerType != nil:
+		return PointerKind
+	case a.MapType != nil:
+		return MapKind
+	case a.ChanType != nil:
+		return ChanKind
+	case a.ArrayType != nil && a.ArrayType.Len == nil:
+		return SliceKind
+	case a.ArrayType != nil:
+		return ArrayKind
+	case a.StructObject != nil:
+		return StructKind
+	case a.InterfaceObject != nil:
+		return InterfaceKind
+	case a.BaseType:
+		return BasicKind
+	default:
+		return UnknownKind
+	}
+}
+
+// IsNamed returns true/false if the type of the argument is a named type (e.g `time.Time`)
+// rather than a type literal (e.g `map[string]int`), aliases are resolved to their target.
+func (a ArgType) IsNamed() bool {
+	if a.TypesType != nil {
+		switch types.Unalias(a.TypesType).(type) {
+		case *types.Named, *types.Basic:
+			return true
+		}
+
+		return false
+	}
+
+	return a.IdentType != nil || a.ImportedObject != nil || a.GenericType != nil
+}
+
+// Underlying returns the underlying type of the argument, it returns nil if the argument
+// was not loaded with type checking.
+func (a ArgType) Underlying() types.Type {
+	if a.TypesType == nil {
+		return nil
+	}
+
+	return a.TypesType.Underlying()
+}
+
+// MethodSet returns the method set of the struct as computed by the type checker, which includes
+// promoted methods of embedded fields. The method set of *T is returned if pointer is true.
+func (str StructDeclaration) MethodSet(pointer bool) (*types.MethodSet, error) {
+	return methodSetOf(str.TypesType, pointer)
+}
+
+// MethodSet returns the method set of the type as computed by the type checker. The method set
+// of *T is returned if pointer is true.
+func (ty TypeDeclaration) MethodSet(pointer bool) (*types.MethodSet, error) {
+	return methodSetOf(ty.TypesType, pointer)
+}
+
+// MethodSet returns the method set of the interface as computed by the type checker, which
+// includes the methods of all embedded interfaces.
+func (i InterfaceDeclaration) MethodSet() (*types.MethodSet, error) {
+	return methodSetOf(i.TypesType, false)
+}
+
+func methodSetOf(typ types.Type, pointer bool) (*types.MethodSet, error) {
+	if typ == nil {
+		return nil, ErrNotTypeChecked
+	}
+
+	if pointer {
+		typ = types.NewPointer(typ)
+	}
+
+	return types.NewMethodSet(typ), nil
+}
+
+// objectOf returns the types.Object defined by the giving identifier and it's type, if the
+// package was type checked.
+func (pkg *PackageDeclaration) objectOf(ident *ast.Ident) (types.Object, types.Type) {
+	if pkg == nil || pkg.TypesInfo == nil || ident == nil {
+		return nil, nil
+	}
+
+	obj := pkg.TypesInfo.Defs[ident]
+	if obj == nil {
+		return nil, nil
+	}
+
+	return obj, obj.Type()
+}
+
+// typeOf returns the type of the giving expression, if the package was type checked.
+func (pkg *PackageDeclaration) typeOf(expr ast.Expr) types.Type {
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil
+	}
+
+	return pkg.TypesInfo.TypeOf(expr)
+}
+
+// signatureOf returns the signature of the function or method defined by the giving
+// identifier, if the package was type checked.
+func (pkg *PackageDeclaration) signatureOf(ident *ast.Ident) *types.Signature {
+	_, typ := pkg.objectOf(ident)
+	signature, _ := typ.(*types.Signature)
+	return signature
+}
+
+// fieldObjectOf returns the types.Var of the giving struct field, if the package was type
+// checked. Embedded fields are defined by the identifier of their type.
+func (pkg *PackageDeclaration) fieldObjectOf(field *ast.Field) types.Object {
+	if len(field.Names) != 0 {
+		obj, _ := pkg.objectOf(field.Names[0])
+		return obj
+	}
+
+	expr := field.Type
+	for {
+		switch inner := expr.(type) {
+		case *ast.StarExpr:
+			expr = inner.X
+			continue
+		case *ast.SelectorExpr:
+			expr = inner.Sel
+			continue
+		case *ast.IndexExpr:
+			expr = inner.X
+			continue
+		case *ast.IndexListExpr:
+			expr = inner.X
+			continue
+		}
+
+		break
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	obj, _ := pkg.objectOf(ident)
+	return obj
+}
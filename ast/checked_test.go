@@ -0,0 +1,203 @@
+package ast_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+)
+
+var checkedSource = `package mock
+
+import (
+	"io"
+	"time"
+)
+
+// Settings defines a alias of a map.
+type Settings = map[string]string
+
+// Base defines a embedded struct.
+type Base struct{}
+
+// Close closes the base.
+func (b *Base) Close() error { return nil }
+
+// Stream defines a interface embedding a stdlib interface.
+type Stream interface {
+	io.Reader
+	Name() string
+}
+
+// Server defines a struct with fields of different kinds.
+type Server struct {
+	*Base
+	Timeout  time.Duration
+	Options  Settings
+	Events   chan string
+	Handlers []func()
+	Stream   Stream
+}
+
+// Name returns the name of the server.
+func (s Server) Name() string { return "" }
+
+// Port defines the default port.
+var Port = 8080
+`
+
+// TestTypedPackage validates the type information carried by declarations loaded with type checking.
+func TestTypedPackage(t *testing.T) {
+	dir := writePackage(t, map[string]string{"mock.go": checkedSource})
+
+	pkgs, err := ast.TypedPackageWithBuildCtx(metrics.New(), dir, build.Default)
+	if err != nil {
+		tests.Failed("Should have loaded package: %+q", err)
+	}
+	tests.Passed("Should have loaded package")
+
+	if pkgs[0].TypesPackage == nil || pkgs[0].TypesPackage.Scope().Lookup("Server") == nil {
+		tests.Failed("Should have type checked package")
+	}
+	tests.Passed("Should have type checked package")
+
+	server, ok := pkgs[0].StructFor("Server")
+	if !ok || server.TypesObject == nil || server.TypesType == nil {
+		tests.Failed("Should have found type checked Server struct")
+	}
+	tests.Passed("Should have found type checked Server struct")
+
+	fields, err := server.Fields()
+	if err != nil {
+		tests.Failed("Should have retrieved fields: %+q", err)
+	}
+
+	kinds := []ast.ArgKind{ast.PointerKind, ast.BasicKind, ast.MapKind, ast.ChanKind, ast.SliceKind, ast.InterfaceKind}
+	if len(fields) != len(kinds) {
+		tests.Failed("Should have retrieved all fields of Server")
+	}
+
+	for index, field := range fields {
+		if field.TypesObject == nil {
+			tests.Failed("Should have type checked field %q", field.FieldName)
+		}
+
+		if kind := field.Arg.Kind(); kind != kinds[index] {
+			tests.Info("Field: %q, Kind: %s, Expected: %s", field.FieldName, kind, kinds[index])
+			tests.Failed("Should have resolved underlying kind of field")
+		}
+	}
+	tests.Passed("Should have resolved underlying kind of fields")
+
+	if timeout, _ := ast.Fields(fields).ByName("Timeout"); !timeout.Arg.IsNamed() {
+		tests.Failed("Should have resolved time.Duration as named type")
+	}
+	tests.Passed("Should have resolved time.Duration as named type")
+
+	if options, _ := ast.Fields(fields).ByName("Options"); options.Arg.IsNamed() {
+		tests.Failed("Should have resolved Settings alias to map literal")
+	}
+	tests.Passed("Should have resolved Settings alias to map literal")
+
+	methods, err := server.MethodSet(false)
+	if err != nil {
+		tests.Failed("Should have retrieved method set: %+q", err)
+	}
+
+	if methods.Len() != 2 || methods.Lookup(nil, "Close") == nil || methods.Lookup(nil, "Name") == nil {
+		tests.Info("Methods: %s", methods)
+		tests.Failed("Should have included promoted methods in method set")
+	}
+	tests.Passed("Should have included promoted methods in method set")
+
+	stream, ok := pkgs[0].InterfaceFor("Stream")
+	if !ok {
+		tests.Failed("Should have found Stream interface")
+	}
+
+	streamMethods, err := stream.MethodSet()
+	if err != nil || streamMethods.Len() != 2 {
+		tests.Failed("Should have included embedded io.Reader methods in method set")
+	}
+	tests.Passed("Should have included embedded io.Reader methods in method set")
+
+	name, ok := pkgs[0].Packages[0].MethodFor("Server")
+	if !ok || len(name) == 0 || name[0].TypesObject == nil {
+		tests.Failed("Should have type checked Server methods")
+	}
+
+	def, err := name[0].Definition(name[0].Declr)
+	if err != nil || def.TypesSignature == nil || def.TypesSignature.Results().Len() != 1 {
+		tests.Failed("Should have type checked method signature")
+	}
+	tests.Passed("Should have type checked method signature")
+
+	for _, variable := range pkgs[0].Packages[0].Variables {
+		if variable.Name == "Port" && (variable.TypesType == nil || variable.TypesType.String() != "int") {
+			tests.Failed("Should have type checked Port variable")
+		}
+	}
+	tests.Passed("Should have type checked Port variable")
+
+	untyped, err := ast.FilteredPackageWithBuildCtx(metrics.New(), dir, build.Default)
+	if err != nil {
+		tests.Failed("Should have loaded package: %+q", err)
+	}
+
+	plain, _ := untyped[0].StructFor("Server")
+	if _, err := plain.MethodSet(false); err != ast.ErrNotTypeChecked {
+		tests.Failed("Should have failed to retrieve method set without type checking")
+	}
+	tests.Passed("Should have failed to retrieve method set without type checking")
+}
+
+var platformSources = map[string]string{
+	"go.mod": "module example.com/platformed\n",
+	"platformed.go": `package platformed
+
+import "example.com/platformed/platform"
+
+// Value defines a variable of a type declared per platform.
+var Value platform.Handle
+
+// Custom defines a constant declared per build tag.
+const Custom = platform.Custom
+`,
+	"platform/handle_linux.go":   "package platform\n\n// Handle defines the handle of linux.\ntype Handle int\n",
+	"platform/handle_windows.go": "package platform\n\n// Handle defines the handle of windows.\ntype Handle string\n",
+	"platform/custom.go":         "//go:build custom\n// +build custom\n\npackage platform\n\n// Custom is true with the custom tag.\nconst Custom = true\n",
+	"platform/default.go":        "//go:build !custom\n// +build !custom\n\npackage platform\n\n// Custom is false without the custom tag.\nconst Custom = false\n",
+}
+
+// TestTypedPackageWithBuildContext validates the type checking of imports with the files
+// selected by the build.Context.
+func TestTypedPackageWithBuildContext(t *testing.T) {
+	dir := writePackage(t, platformSources)
+
+	ctx := build.Default
+	ctx.GOOS = "windows"
+	ctx.BuildTags = []string{"custom"}
+
+	pkgs, err := ast.TypedPackageWithBuildCtx(metrics.New(), dir, ctx)
+	if err != nil {
+		tests.Failed("Should have loaded package: %+q", err)
+	}
+	tests.Passed("Should have loaded package")
+
+	scope := pkgs[0].TypesPackage.Scope()
+
+	if value := scope.Lookup("Value"); value == nil || value.Type().Underlying().String() != "string" {
+		tests.Info("Received: %+v", value)
+		tests.Failed("Should have type checked import with files of GOOS")
+	}
+	tests.Passed("Should have type checked import with files of GOOS")
+
+	if custom := scope.Lookup("Custom"); custom == nil || custom.(*types.Const).Val().String() != "true" {
+		tests.Info("Received: %+v", custom)
+		tests.Failed("Should have type checked import with files of build tags")
+	}
+	tests.Passed("Should have type checked import with files of build tags")
+}
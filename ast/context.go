@@ -0,0 +1,325 @@
+package ast
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/moz/gen"
+)
+
+// GeneratorContext defines the context a generator is invoked with, carrying the annotation and
+// package of the declaration with the details of the run of the generators.
+type GeneratorContext struct {
+	// Context is cancelled when the run of the generators is.
+	Context context.Context
+
+	// Metrics is the metrics of the AnnotationRegistry running the generator.
+	Metrics metrics.Metrics
+
+	// Annotation is the annotation the generator is invoked for.
+	Annotation AnnotationDeclaration
+
+	// Declaration is the file of the declaration of the annotation.
+	Declaration PackageDeclaration
+
+	// Package is the package of the declaration.
+	Package Package
+
+	// Packages contains all packages of the run, including Package.
+	Packages Packages
+
+	// ToPath is the import path of the destination, as provided to AnnotationGenerators.
+	ToPath string
+
+	// ToDir is the directory of the destination, if known.
+	ToDir string
+
+	// Module is the module path of the destination, if within a go module.
+	Module string
+
+	// Options contains the options provided by the user for the run (see ParseConfig).
+	Options map[string]string
+}
+
+// Option returns the value of the user option with giving name.
+func (g GeneratorContext) Option(name string) (string, bool) {
+	value, ok := g.Options[name]
+	return value, ok
+}
+
+// Emitted returns the directives emitted before the annotation, for the giving annotations
+// or all if none is provided. Only deferred annotations are run after other directives are
+// emitted, for others none is returned (see AnnotationRegistry.ParsePackages).
+func (g GeneratorContext) Emitted(annotations ...string) []AnnotationWriteDirective {
+	if len(annotations) == 0 {
+		return g.Annotation.Emitted
+	}
+
+	var emitted []AnnotationWriteDirective
+	for _, directive := range g.Annotation.Emitted {
+		for _, annotation := range annotations {
+			if strings.TrimPrefix(directive.Annotation, "@") == strings.TrimPrefix(annotation, "@") {
+				emitted = append(emitted, directive)
+				break
+			}
+		}
+	}
+
+	return emitted
+}
+
+// positionalContext returns the GeneratorContext for a context generator invoked with the
+// arguments of a AnnotationGenerator, within the context and metrics provided.
+func positionalContext(ctx context.Context, log metrics.Metrics, toDir string, an AnnotationDeclaration, pkgDeclr PackageDeclaration, pkg Package) GeneratorContext {
+	return GeneratorContext{
+		Context:     ctx,
+		Metrics:     log,
+		Annotation:  an,
+		Declaration: pkgDeclr,
+		Package:     pkg,
+		Packages:    Packages{pkg},
+		ToPath:      toDir,
+	}
+}
+
+//===========================================================================================================
+
+// PackageContextGenerator defines a function which generates code for a package level annotation
+// with the GeneratorContext of the annotation.
+type PackageContextGenerator func(GeneratorContext) ([]gen.WriteDirective, error)
+
+// StructContextGenerator defines a function which generates code for a annotation of a struct type
+// with the GeneratorContext of the annotation.
+type StructContextGenerator func(GeneratorContext, StructDeclaration) ([]gen.WriteDirective, error)
+
+// InterfaceContextGenerator defines a function which generates code for a annotation of a interface
+// type with the GeneratorContext of the annotation.
+type InterfaceContextGenerator func(GeneratorContext, InterfaceDeclaration) ([]gen.WriteDirective, error)
+
+// TypeContextGenerator defines a function which generates code for a annotation of a non-struct,
+// non-interface type with the GeneratorContext of the annotation.
+type TypeContextGenerator func(GeneratorContext, TypeDeclaration) ([]gen.WriteDirective, error)
+
+// FunctionContextGenerator defines a function which generates code for a annotation of a function
+// or method with the GeneratorContext of the annotation.
+type FunctionContextGenerator func(GeneratorContext, FuncDeclaration) ([]gen.WriteDirective, error)
+
+// VariableContextGenerator defines a function which generates code for a annotation of a package
+// level variable or constant with the GeneratorContext of the annotation.
+type VariableContextGenerator func(GeneratorContext, VariableDeclaration) ([]gen.WriteDirective, error)
+
+// Contextual returns the generator as a PackageContextGenerator.
+func (generator PackageAnnotationGenerator) Contextual() PackageContextGenerator {
+	return func(ctx GeneratorContext) ([]gen.WriteDirective, error) {
+		return generator(ctx.ToPath, ctx.Annotation, ctx.Declaration, ctx.Package)
+	}
+}
+
+// Contextual returns the generator as a StructContextGenerator.
+func (generator StructAnnotationGenerator) Contextual() StructContextGenerator {
+	return func(ctx GeneratorContext, str StructDeclaration) ([]gen.WriteDirective, error) {
+		return generator(ctx.ToPath, ctx.Annotation, str, ctx.Declaration, ctx.Package)
+	}
+}
+
+// Contextual returns the generator as a InterfaceContextGenerator.
+func (generator InterfaceAnnotationGenerator) Contextual() InterfaceContextGenerator {
+	return func(ctx GeneratorContext, inter InterfaceDeclaration) ([]gen.WriteDirective, error) {
+		return generator(ctx.ToPath, ctx.Annotation, inter, ctx.Declaration, ctx.Package)
+	}
+}
+
+// Contextual returns the generator as a TypeContextGenerator.
+func (generator TypeAnnotationGenerator) Contextual() TypeContextGenerator {
+	return func(ctx GeneratorContext, typ TypeDeclaration) ([]gen.WriteDirective, error) {
+		return generator(ctx.ToPath, ctx.Annotation, typ, ctx.Declaration, ctx.Package)
+	}
+}
+
+// Contextual returns the generator as a FunctionContextGenerator.
+func (generator FunctionAnnotationGenerator) Contextual() FunctionContextGenerator {
+	return func(ctx GeneratorContext, fun FuncDeclaration) ([]gen.WriteDirective, error) {
+		return generator(ctx.ToPath, ctx.Annotation, fun, ctx.Declaration, ctx.Package)
+	}
+}
+
+// Contextual returns the generator as a VariableContextGenerator.
+func (generator VariableAnnotationGenerator) Contextual() VariableContextGenerator {
+	return func(ctx GeneratorContext, variable VariableDeclaration) ([]gen.WriteDirective, error) {
+		return generator(ctx.ToPath, ctx.Annotation, variable, ctx.Declaration, ctx.Package)
+	}
+}
+
+// Positional returns the generator as a PackageAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with a background context and new metrics.
+func (generator PackageContextGenerator) Positional() PackageAnnotationGenerator {
+	return generator.PositionalWith(context.Background(), metrics.New())
+}
+
+// PositionalWith returns the generator as a PackageAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with the provided context and metrics.
+func (generator PackageContextGenerator) PositionalWith(ctx context.Context, log metrics.Metrics) PackageAnnotationGenerator {
+	return func(toDir string, an AnnotationDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+		return generator(positionalContext(ctx, log, toDir, an, pkgDeclr, pkg))
+	}
+}
+
+// Positional returns the generator as a StructAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with a background context and new metrics.
+func (generator StructContextGenerator) Positional() StructAnnotationGenerator {
+	return generator.PositionalWith(context.Background(), metrics.New())
+}
+
+// PositionalWith returns the generator as a StructAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with the provided context and metrics.
+func (generator StructContextGenerator) PositionalWith(ctx context.Context, log metrics.Metrics) StructAnnotationGenerator {
+	return func(toDir string, an AnnotationDeclaration, str StructDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+		return generator(positionalContext(ctx, log, toDir, an, pkgDeclr, pkg), str)
+	}
+}
+
+// Positional returns the generator as a InterfaceAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with a background context and new metrics.
+func (generator InterfaceContextGenerator) Positional() InterfaceAnnotationGenerator {
+	return generator.PositionalWith(context.Background(), metrics.New())
+}
+
+// PositionalWith returns the generator as a InterfaceAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with the provided context and metrics.
+func (generator InterfaceContextGenerator) PositionalWith(ctx context.Context, log metrics.Metrics) InterfaceAnnotationGenerator {
+	return func(toDir string, an AnnotationDeclaration, inter InterfaceDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+		return generator(positionalContext(ctx, log, toDir, an, pkgDeclr, pkg), inter)
+	}
+}
+
+// Positional returns the generator as a TypeAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with a background context and new metrics.
+func (generator TypeContextGenerator) Positional() TypeAnnotationGenerator {
+	return generator.PositionalWith(context.Background(), metrics.New())
+}
+
+// PositionalWith returns the generator as a TypeAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with the provided context and metrics.
+func (generator TypeContextGenerator) PositionalWith(ctx context.Context, log metrics.Metrics) TypeAnnotationGenerator {
+	return func(toDir string, an AnnotationDeclaration, typ TypeDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+		return generator(positionalContext(ctx, log, toDir, an, pkgDeclr, pkg), typ)
+	}
+}
+
+// Positional returns the generator as a FunctionAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with a background context and new metrics.
+func (generator FunctionContextGenerator) Positional() FunctionAnnotationGenerator {
+	return generator.PositionalWith(context.Background(), metrics.New())
+}
+
+// PositionalWith returns the generator as a FunctionAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with the provided context and metrics.
+func (generator FunctionContextGenerator) PositionalWith(ctx context.Context, log metrics.Metrics) FunctionAnnotationGenerator {
+	return func(toDir string, an AnnotationDeclaration, fun FuncDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+		return generator(positionalContext(ctx, log, toDir, an, pkgDeclr, pkg), fun)
+	}
+}
+
+// Positional returns the generator as a VariableAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with a background context and new metrics.
+func (generator VariableContextGenerator) Positional() VariableAnnotationGenerator {
+	return generator.PositionalWith(context.Background(), metrics.New())
+}
+
+// PositionalWith returns the generator as a VariableAnnotationGenerator, invoked with a GeneratorContext
+// containing only the package of the declaration, with the provided context and metrics.
+func (generator VariableContextGenerator) PositionalWith(ctx context.Context, log metrics.Metrics) VariableAnnotationGenerator {
+	return func(toDir string, an AnnotationDeclaration, variable VariableDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+		return generator(positionalContext(ctx, log, toDir, an, pkgDeclr, pkg), variable)
+	}
+}
+
+//===========================================================================================================
+
+// ParseConfig defines the configuration of a run of the generators of a AnnotationRegistry.
+type ParseConfig struct {
+	// Context cancels the run when done, defaults to context.Background.
+	Context context.Context
+
+	// Dir is the directory of the destination, used to provide it's module to generators.
+	Dir string
+
+	// Options contains options provided by the user to generators (see GeneratorContext.Option).
+	Options map[string]string
+
+	// Workers is the number of declarations whose generators run concurrently, defaults to 1.
+	// Generators must be safe for concurrent use when above 1.
+	Workers int
+
+	// KeepGoing runs the generators of all annotations when some fail, instead of stopping at the first
+	// failure. The failures are returned as GenerationErrors, with the directives of all others.
+	KeepGoing bool
+
+	// AllOrNothing returns no directive with the GenerationErrors when keeping going, so nothing is
+	// written unless all generators succeed.
+	AllOrNothing bool
+}
+
+// generation defines a run of the generators of a AnnotationRegistry over a set of packages.
+type generation struct {
+	ctx     context.Context
+	log     metrics.Metrics
+	pkgs    Packages
+	toPath  string
+	toDir   string
+	module  string
+	options map[string]string
+	workers int
+
+	keepGoing    bool
+	allOrNothing bool
+}
+
+// newGeneration returns the generation for the configuration.
+func newGeneration(config ParseConfig, log metrics.Metrics, pkgs Packages, toPath string) generation {
+	run := generation{
+		ctx:     config.Context,
+		log:     log,
+		pkgs:    pkgs,
+		toPath:  toPath,
+		toDir:   config.Dir,
+		options: config.Options,
+		workers: config.Workers,
+
+		keepGoing:    config.KeepGoing,
+		allOrNothing: config.AllOrNothing,
+	}
+
+	if run.ctx == nil {
+		run.ctx = context.Background()
+	}
+
+	if run.workers < 1 {
+		run.workers = 1
+	}
+
+	if config.Dir != "" {
+		if mod, err := ModuleFor(config.Dir); err == nil {
+			run.module = mod.Path
+		}
+	}
+
+	return run
+}
+
+// contextFor returns the GeneratorContext of the annotation of a declaration within the package.
+func (g generation) contextFor(annotation AnnotationDeclaration, declr PackageDeclaration, pkg Package) GeneratorContext {
+	return GeneratorContext{
+		Context:     g.ctx,
+		Metrics:     g.log,
+		Annotation:  annotation,
+		Declaration: declr,
+		Package:     pkg,
+		Packages:    g.pkgs,
+		ToPath:      g.toPath,
+		ToDir:       g.toDir,
+		Module:      g.module,
+		Options:     g.options,
+	}
+}
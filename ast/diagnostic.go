@@ -0,0 +1,317 @@
+package ast
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Severity defines the severity of a Diagnostic.
+type Severity int
+
+// Contains the different severities of a Diagnostic.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "info",
+}
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+
+	return severityNames[SeverityError]
+}
+
+// Diagnostic defines a problem found while parsing a declaration, it's annotations or while
+// running the generator of a annotation, positioned at the file, line and column it relates to.
+type Diagnostic struct {
+	Severity   Severity       `json:"severity"`
+	Position   token.Position `json:"position"`
+	Annotation string         `json:"annotation"`
+	Message    string         `json:"message"`
+
+	// Err is the underline error the diagnostic was created from, if any.
+	Err error `json:"-"`
+}
+
+// Error returns the diagnostic in the form `file.go:12:3: @mongo: message`, the severity
+// is included after the position when not a error.
+func (d Diagnostic) Error() string {
+	var bu bytes.Buffer
+
+	if d.Position.IsValid() || d.Position.Filename != "" {
+		bu.WriteString(d.Position.String())
+		bu.WriteString(": ")
+	}
+
+	if d.Severity != SeverityError {
+		bu.WriteString(d.Severity.String())
+		bu.WriteString(": ")
+	}
+
+	if d.Annotation != "" {
+		bu.WriteString("@" + strings.TrimPrefix(d.Annotation, "@"))
+		bu.WriteString(": ")
+	}
+
+	bu.WriteString(d.Message)
+	return bu.String()
+}
+
+// String returns the diagnostic as returned by Diagnostic.Error.
+func (d Diagnostic) String() string {
+	return d.Error()
+}
+
+// Unwrap returns the error the diagnostic was created from.
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// Diagnostics defines a slice of Diagnostic.
+type Diagnostics []Diagnostic
+
+// HasErrors returns true/false if any of the diagnostics has the SeverityError severity.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Errors returns the diagnostics with the SeverityError severity.
+func (ds Diagnostics) Errors() Diagnostics {
+	var errs Diagnostics
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+
+	return errs
+}
+
+// Error returns the diagnostics, one per line.
+func (ds Diagnostics) Error() string {
+	lines := make([]string, 0, len(ds))
+	for _, d := range ds {
+		lines = append(lines, d.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Diagnostics returns the diagnostics of all files within the package, including those
+// of it's test files.
+func (pkg Package) Diagnostics() Diagnostics {
+	var ds Diagnostics
+
+	for _, declr := range pkg.Packages {
+		ds = append(ds, declr.Diagnostics...)
+	}
+
+	for _, declr := range pkg.TestPackages {
+		ds = append(ds, declr.Diagnostics...)
+	}
+
+	return ds
+}
+
+// Diagnostics returns the diagnostics of all packages.
+func (pkgs Packages) Diagnostics() Diagnostics {
+	var ds Diagnostics
+	for _, pkg := range pkgs {
+		ds = append(ds, pkg.Diagnostics()...)
+	}
+
+	return ds
+}
+
+// DiagnosticFor returns the error of a generator for the annotation of a declaration as
+// a Diagnostic, positioned at the annotation if known, else at the declaration. Errors
+// which are already a Diagnostic are returned as is.
+func DiagnosticFor(err error, annotation AnnotationDeclaration, location token.Position) Diagnostic {
+	if diag, ok := err.(Diagnostic); ok {
+		return diag
+	}
+
+	if diag, ok := err.(*Diagnostic); ok && diag != nil {
+		return *diag
+	}
+
+	position := annotation.Position
+	if !position.IsValid() {
+		position = location
+	}
+
+	return Diagnostic{
+		Severity:   SeverityError,
+		Position:   position,
+		Annotation: annotation.Name,
+		Message:    err.Error(),
+		Err:        err,
+	}
+}
+
+//===========================================================================================================
+
+// ReadAnnotationsFromComments returns the annotations within the giving comment group with
+// their position within the source file, and the diagnostics of malformed annotations.
+func ReadAnnotationsFromComments(tokenFiles *token.FileSet, group *ast.CommentGroup) ([]AnnotationDeclaration, Diagnostics) {
+	if group == nil {
+		return nil, nil
+	}
+
+	annotations, diagnostics := ReadAnnotationsWithDiagnostics(bytes.NewBufferString(group.Text()))
+
+	// The text of the comment group has it's comment markers and blank lines removed,
+	// so annotations are matched in order to the comment lines they start at.
+	var lines []annotationLine
+	for _, comment := range group.List {
+		start := tokenFiles.Position(comment.Pos())
+
+		for index, line := range strings.Split(comment.Text, "\n") {
+			trimmed := string(cleanWord([]byte(line)))
+			if !strings.HasPrefix(trimmed, "@") {
+				continue
+			}
+
+			position := start
+			position.Offset = 0
+			position.Line += index
+			if index == 0 {
+				position.Column += strings.Index(line, "@")
+			} else {
+				position.Column = strings.Index(line, "@") + 1
+			}
+
+			lines = append(lines, annotationLine{text: trimmed, position: position})
+		}
+	}
+
+	positions := make(map[int]token.Position, len(annotations))
+
+	var next int
+	for index, annotation := range annotations {
+		for ; next < len(lines); next++ {
+			if !lines[next].startsWith(annotation.Name) {
+				continue
+			}
+
+			positions[annotation.Position.Line] = lines[next].position
+			annotations[index].Position = lines[next].position
+			next++
+			break
+		}
+	}
+
+	for index, diag := range diagnostics {
+		if position, ok := positions[diag.Position.Line]; ok {
+			diagnostics[index].Position = position
+			continue
+		}
+
+		diagnostics[index].Position = tokenFiles.Position(group.Pos())
+	}
+
+	return annotations, diagnostics
+}
+
+// ReadFieldAnnotations returns the annotations within the doc and line comments of a struct field
+// or interface method, with the diagnostics of malformed annotations. Annotations are positioned
+// only when the file set of the source is provided.
+func ReadFieldAnnotations(tokenFiles *token.FileSet, field *ast.Field) ([]AnnotationDeclaration, Diagnostics) {
+	if field == nil {
+		return nil, nil
+	}
+
+	var annotations []AnnotationDeclaration
+	var diagnostics Diagnostics
+
+	for _, group := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if group == nil {
+			continue
+		}
+
+		var read []AnnotationDeclaration
+		var readDiagnostics Diagnostics
+
+		if tokenFiles != nil {
+			read, readDiagnostics = ReadAnnotationsFromComments(tokenFiles, group)
+		} else {
+			read, readDiagnostics = ReadAnnotationsWithDiagnostics(bytes.NewBufferString(group.Text()))
+		}
+
+		annotations = append(annotations, read...)
+		diagnostics = append(diagnostics, readDiagnostics...)
+	}
+
+	return annotations, diagnostics
+}
+
+// fieldListDiagnostics returns the diagnostics of malformed annotations within the comments
+// of the fields of a struct or methods of a interface.
+func fieldListDiagnostics(tokenFiles *token.FileSet, list *ast.FieldList) Diagnostics {
+	if list == nil {
+		return nil
+	}
+
+	var diagnostics Diagnostics
+	for _, field := range list.List {
+		_, fieldDiagnostics := ReadFieldAnnotations(tokenFiles, field)
+		diagnostics = append(diagnostics, fieldDiagnostics...)
+	}
+
+	return diagnostics
+}
+
+// annotationLine defines a comment line which starts with a annotation.
+type annotationLine struct {
+	text     string
+	position token.Position
+}
+
+// startsWith returns true/false if the line starts with the annotation name, followed by
+// the end of the line or it's arguments.
+func (al annotationLine) startsWith(name string) bool {
+	if !strings.HasPrefix(al.text, name) {
+		return false
+	}
+
+	rest := strings.TrimSpace(al.text[len(name):])
+	return rest == "" || strings.HasPrefix(rest, "(")
+}
+
+// typeErrorDiagnostic returns the error of the type checker as a Diagnostic.
+func typeErrorDiagnostic(err error) Diagnostic {
+	terr, ok := err.(types.Error)
+	if !ok {
+		return Diagnostic{Severity: SeverityError, Message: err.Error(), Err: err}
+	}
+
+	severity := SeverityError
+	if terr.Soft {
+		severity = SeverityWarning
+	}
+
+	return Diagnostic{
+		Severity: severity,
+		Position: terr.Fset.Position(terr.Pos),
+		Message:  terr.Msg,
+		Err:      err,
+	}
+}
@@ -0,0 +1,186 @@
+package ast_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+var diagnosticSource = `package mock
+
+// User defines a user of the service.
+//  @mongo(colection => users)
+//  @json(asJSON, {
+//    {"name": }
+//  })
+type User struct {
+	Name string
+}
+
+// Group defines a group of users.
+// @associates(@mongo, New)
+type Group struct{}
+`
+
+// TestDiagnostics validates the positioning of diagnostics produced when parsing annotations
+// and running their generators.
+func TestDiagnostics(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": diagnosticSource})
+
+	user, ok := pkgs[0].StructFor("User")
+	if !ok {
+		tests.Failed("Should have found User struct")
+	}
+	tests.Passed("Should have found User struct")
+
+	if user.Location.Line != 8 || user.Location.Column != 6 {
+		tests.Info("Location: %s", user.Location)
+		tests.Failed("Should have resolved position of User struct")
+	}
+	tests.Passed("Should have resolved position of User struct")
+
+	if len(user.Annotations) != 2 || user.Annotations[0].Position.Line != 4 || user.Annotations[0].Position.Column != 5 {
+		tests.Info("Annotations: %+v", user.Annotations)
+		tests.Failed("Should have resolved position of @mongo annotation")
+	}
+	tests.Passed("Should have resolved position of @mongo annotation")
+
+	diagnostics := pkgs.Diagnostics()
+	if len(diagnostics) != 2 || !diagnostics.HasErrors() {
+		tests.Info("Diagnostics: %s", diagnostics)
+		tests.Failed("Should have produced diagnostics for invalid JSON template and incomplete association")
+	}
+	tests.Passed("Should have produced diagnostics for invalid JSON template and incomplete association")
+
+	if diag := diagnostics[0]; diag.Annotation != "@json" || diag.Position.Line != 5 || diag.Position.Column != 5 {
+		tests.Info("Diagnostic: %s", diag)
+		tests.Failed("Should have positioned diagnostic at @json annotation")
+	}
+	tests.Passed("Should have positioned diagnostic at @json annotation")
+
+	if diag := diagnostics[1]; diag.Annotation != "@associates" || diag.Position.Line != 13 || diag.Position.Column != 4 {
+		tests.Info("Diagnostic: %s", diag)
+		tests.Failed("Should have positioned diagnostic at @associates annotation")
+	}
+	tests.Passed("Should have positioned diagnostic at @associates annotation")
+
+	generator := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		if _, ok := an.Params["colection"]; ok {
+			return nil, errors.New(`unknown param "colection"`)
+		}
+
+		return nil, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("mongo", generator)
+
+	_, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+
+	diag, ok := err.(ast.Diagnostic)
+	if !ok {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have returned generator error as diagnostic")
+	}
+	tests.Passed("Should have returned generator error as diagnostic")
+
+	diag.Position.Filename = filepath.Base(diag.Position.Filename)
+	if diag.Error() != `mock.go:4:5: @mongo: unknown param "colection"` {
+		tests.Info("Diagnostic: %s", diag)
+		tests.Failed("Should have formatted diagnostic with position and annotation")
+	}
+	tests.Passed("Should have formatted diagnostic with position and annotation")
+}
+
+var fieldAnnotationSource = `package mock
+
+// User defines a user of the service.
+type User struct {
+	// @index(unique)
+	Email string
+
+	Name string // @search(weight => 2)
+
+	Age int // @range(min => [)
+}
+
+// UserService defines the service for users.
+type UserService interface {
+	// Get returns the user with the giving id.
+	// @http(GET /users/{id})
+	Get(id string) (User, error)
+
+	// @audit
+	Delete(id string) error // @http(DELETE /users/{id})
+}
+`
+
+// TestFieldAndMethodAnnotations validates the reading of annotations within the doc and line
+// comments of struct fields and interface methods.
+func TestFieldAndMethodAnnotations(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": fieldAnnotationSource})
+
+	user, ok := pkgs[0].StructFor("User")
+	if !ok {
+		tests.Failed("Should have found User struct")
+	}
+	tests.Passed("Should have found User struct")
+
+	fields := ast.GetFields(user, user.Declr)
+	if len(fields) != 3 {
+		tests.Info("Fields: %d", len(fields))
+		tests.Failed("Should have retrieved fields of User struct")
+	}
+	tests.Passed("Should have retrieved fields of User struct")
+
+	index, ok := fields[0].GetAnnotation("index")
+	if !ok || !index.HasArg("unique") || index.Position.Line != 5 || index.Position.Column != 5 {
+		tests.Info("Annotations: %+v", fields[0].Annotations)
+		tests.Failed("Should have read @index annotation from doc comment of Email field")
+	}
+	tests.Passed("Should have read @index annotation from doc comment of Email field")
+
+	search, ok := fields[1].GetAnnotation("@search")
+	if !ok || search.Param("weight") != "2" || search.Position.Line != 8 {
+		tests.Info("Annotations: %+v", fields[1].Annotations)
+		tests.Failed("Should have read @search annotation from line comment of Name field")
+	}
+	tests.Passed("Should have read @search annotation from line comment of Name field")
+
+	service, ok := pkgs[0].InterfaceFor("UserService")
+	if !ok {
+		tests.Failed("Should have found UserService interface")
+	}
+	tests.Passed("Should have found UserService interface")
+
+	methods := ast.GetInterfaceFunctions(service.Interface, service.Declr)
+	if len(methods) != 2 {
+		tests.Info("Methods: %d", len(methods))
+		tests.Failed("Should have retrieved methods of UserService interface")
+	}
+	tests.Passed("Should have retrieved methods of UserService interface")
+
+	get, ok := methods[0].GetAnnotation("http")
+	if !ok || !get.HasArg("GET /users/{id}") || get.Position.Line != 16 {
+		tests.Info("Annotations: %+v", methods[0].Annotations)
+		tests.Failed("Should have read @http annotation from doc comment of Get method")
+	}
+	tests.Passed("Should have read @http annotation from doc comment of Get method")
+
+	if !methods[1].HasAnnotation("http") || !methods[1].HasAnnotation("audit") {
+		tests.Info("Annotations: %+v", methods[1].Annotations)
+		tests.Failed("Should have read annotations from doc and line comments of Delete method")
+	}
+	tests.Passed("Should have read annotations from doc and line comments of Delete method")
+
+	diagnostics := pkgs.Diagnostics()
+	if len(diagnostics) != 1 || diagnostics[0].Annotation != "@range" || diagnostics[0].Position.Line != 10 {
+		tests.Info("Diagnostics: %s", diagnostics)
+		tests.Failed("Should have produced diagnostic for malformed @range annotation")
+	}
+	tests.Passed("Should have produced diagnostic for malformed @range annotation")
+}
@@ -0,0 +1,99 @@
+package ast
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// GenerationFailure defines the failure of a annotation of a declaration within a package, recorded
+// when generators are run with ParseConfig.KeepGoing. It's Diagnostic is positioned at the annotation.
+type GenerationFailure struct {
+	Diagnostic
+
+	// Package is the import path of the package of the declaration.
+	Package string `json:"package"`
+
+	// Declaration is the name of the declaration of the annotation, empty for package annotations.
+	Declaration string `json:"declaration"`
+}
+
+// Error returns the diagnostic of the failure with the declaration and package it occurred in.
+func (f GenerationFailure) Error() string {
+	if f.Declaration == "" {
+		return fmt.Sprintf("%s (in package %s)", f.Diagnostic.Error(), f.Package)
+	}
+
+	return fmt.Sprintf("%s (in %s of %s)", f.Diagnostic.Error(), f.Declaration, f.Package)
+}
+
+// GenerationErrors defines the failures of all annotations whose generators could not be run, when
+// generators are run with ParseConfig.KeepGoing.
+type GenerationErrors []GenerationFailure
+
+// Diagnostics returns the diagnostics of the failures.
+func (ge GenerationErrors) Diagnostics() Diagnostics {
+	diagnostics := make(Diagnostics, 0, len(ge))
+	for _, failure := range ge {
+		diagnostics = append(diagnostics, failure.Diagnostic)
+	}
+
+	return diagnostics
+}
+
+// Summary returns the number of failed annotations and the packages they were found in.
+func (ge GenerationErrors) Summary() string {
+	var pkgs []string
+	counts := make(map[string]int)
+
+	for _, failure := range ge {
+		if _, ok := counts[failure.Package]; !ok {
+			pkgs = append(pkgs, failure.Package)
+		}
+
+		counts[failure.Package]++
+	}
+
+	items := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		items = append(items, fmt.Sprintf("%s (%d)", pkg, counts[pkg]))
+	}
+
+	return fmt.Sprintf("Generation failed for %d annotation(s) in %d package(s): %s", len(ge), len(pkgs), strings.Join(items, ", "))
+}
+
+// Error returns the summary of the failures followed by each failure, one per line.
+func (ge GenerationErrors) Error() string {
+	lines := make([]string, 0, len(ge)+1)
+	lines = append(lines, ge.Summary())
+
+	for _, failure := range ge {
+		lines = append(lines, failure.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// failuresFor returns the failures of the error of a annotation of the declaration, one for each of
+// it's diagnostics.
+func failuresFor(err error, pkg Package, declaration string, annotation AnnotationDeclaration, location token.Position) GenerationErrors {
+	var diagnostics Diagnostics
+
+	switch derr := err.(type) {
+	case Diagnostics:
+		diagnostics = derr
+	default:
+		diagnostics = Diagnostics{DiagnosticFor(err, annotation, location)}
+	}
+
+	failures := make(GenerationErrors, 0, len(diagnostics))
+	for _, diag := range diagnostics {
+		failures = append(failures, GenerationFailure{
+			Diagnostic:  diag,
+			Package:     pkg.Path,
+			Declaration: declaration,
+		})
+	}
+
+	return failures
+}
@@ -0,0 +1,218 @@
+package ast
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/gen"
+)
+
+// DefaultMaxIterations defines the maximum number of iterations of ParseUntilStable, when
+// none is provided.
+const DefaultMaxIterations = 10
+
+// PackageLoader defines a function which loads the packages within a directory.
+type PackageLoader func(log metrics.Metrics, dir string) (Packages, error)
+
+// FixpointOptions defines the options of ParseUntilStable.
+type FixpointOptions struct {
+	// MaxIterations is the maximum number of times generators are run, defaults to DefaultMaxIterations.
+	MaxIterations int
+
+	// Format formats all generated .go files, as done by gen.WriteDirective.Format.
+	Format bool
+
+	// Load loads the packages of the directories generated files are written into, defaults
+	// to FilteredPackageWithBuildCtx with build.Default.
+	Load PackageLoader
+
+	// Config configures each run of the generators, as done by AnnotationRegistry.ParsePackagesWith.
+	Config ParseConfig
+}
+
+// ParseUntilStable runs the generators of the packages as done by Parse, then re-parses the Go files
+// written to run generators on the annotations they contain, repeating until no file is written with
+// new content. Files returning to the content of a earlier iteration are reported as a cycle, and a
+// error is returned if generation does not stabilise within the maximum iterations. When keeping going
+// (see ParseConfig.KeepGoing), generation stops with the GenerationErrors of a iteration once it's other
+// directives are written. It returns the number of iterations run. Provided toDir must be a absolute path.
+func ParseUntilStable(toDir string, log metrics.Metrics, provider *AnnotationRegistry, doFileOverwrite bool, options FixpointOptions, pkgDeclrs ...Package) (int, error) {
+	if options.MaxIterations <= 0 {
+		options.MaxIterations = DefaultMaxIterations
+	}
+
+	if options.Load == nil {
+		options.Load = func(log metrics.Metrics, dir string) (Packages, error) {
+			return FilteredPackageWithBuildCtx(log, dir, build.Default)
+		}
+	}
+
+	// history contains the checksum of each content written into a file, in order.
+	history := make(map[string][]string)
+
+	pkgs := Packages(pkgDeclrs)
+
+	for iteration := 1; ; iteration++ {
+		wdrs, err := parseDirectives(toDir, log, provider, options.Config, pkgs)
+		failures, keepGoing := err.(GenerationErrors)
+		if err != nil && !keepGoing {
+			return iteration, err
+		}
+
+		var changed []string
+
+		for _, wd := range wdrs {
+			if options.Format {
+				wd.Format = true
+			}
+
+			file, err := writeIfChanged(log, toDir, doFileOverwrite, wd.WriteDirective, history, iteration)
+			if err != nil {
+				log.Emit(metrics.Error(err), metrics.With("annotation", wd.Annotation),
+					metrics.With("dir", toDir),
+					metrics.With("file", wd.Source),
+					metrics.With("iteration", iteration))
+				return iteration, err
+			}
+
+			if file != "" {
+				changed = append(changed, file)
+			}
+		}
+
+		log.Emit(metrics.Info("Iteration Resolved"), metrics.With("iteration", iteration),
+			metrics.With("dir", toDir),
+			metrics.With("changed", len(changed)))
+
+		if keepGoing {
+			return iteration, failures
+		}
+
+		if len(changed) == 0 {
+			return iteration, nil
+		}
+
+		if pkgs, err = loadGenerated(log, options.Load, changed); err != nil {
+			return iteration, err
+		}
+
+		if len(pkgs) == 0 {
+			return iteration, nil
+		}
+
+		if iteration >= options.MaxIterations {
+			return iteration, fmt.Errorf("Generation did not stabilise after %d iterations, files still changing: %s", iteration, strings.Join(changed, ", "))
+		}
+	}
+}
+
+// writeIfChanged writes the directive if it's content differs from the content last written
+// into it's file, returning the path of the file if written. Files marked DontOverride which
+// already exist are left as is.
+func writeIfChanged(log metrics.Metrics, toDir string, doFileOverwrite bool, item gen.WriteDirective, history map[string][]string, iteration int) (string, error) {
+	if item.Writer == nil || item.FileName == "" || filepath.IsAbs(item.Dir) {
+		return "", WriteDirective(log, toDir, doFileOverwrite, item)
+	}
+
+	namedFile := filepath.Join(toDir, item.Dir, item.FileName)
+
+	if _, err := os.Stat(namedFile); err == nil && item.DontOverride && !doFileOverwrite {
+		return "", nil
+	}
+
+	writer, err := directiveWriter(namedFile, item)
+	if err != nil {
+		return "", err
+	}
+
+	var content bytes.Buffer
+	if _, err := writer.WriteTo(&content); err != nil && err != io.EOF {
+		return "", fmt.Errorf("IOError: Unable to write content to file: %+q", err)
+	}
+
+	checksum := fmt.Sprintf("%x", sha1.Sum(content.Bytes()))
+
+	written := history[namedFile]
+	if len(written) != 0 && written[len(written)-1] == checksum {
+		return "", nil
+	}
+
+	for _, previous := range written {
+		if previous == checksum {
+			return "", fmt.Errorf("Generation cycle detected: %q returned to a earlier content in iteration %d", namedFile, iteration)
+		}
+	}
+
+	history[namedFile] = append(written, checksum)
+
+	item.Writer = gen.NewConstantWriter(content.Bytes())
+	item.Format = false
+
+	if err := WriteDirective(log, toDir, doFileOverwrite, item); err != nil {
+		return "", err
+	}
+
+	return namedFile, nil
+}
+
+// loadGenerated returns the packages of the directories of the Go files, with only the
+// declarations of those files.
+func loadGenerated(log metrics.Metrics, load PackageLoader, files []string) (Packages, error) {
+	generated := make(map[string]bool)
+	dirs := make(map[string]bool)
+
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			continue
+		}
+
+		generated[filepath.Clean(file)] = true
+		dirs[filepath.Dir(file)] = true
+	}
+
+	sortedDirs := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+
+	sort.Strings(sortedDirs)
+
+	var pkgs Packages
+
+	for _, dir := range sortedDirs {
+		loaded, err := load(log, dir)
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); ok {
+				continue
+			}
+
+			return nil, err
+		}
+
+		for _, pkg := range loaded {
+			var declrs []PackageDeclaration
+			for _, declr := range pkg.Packages {
+				if generated[filepath.Clean(filepath.FromSlash(declr.FilePath))] {
+					declrs = append(declrs, declr)
+				}
+			}
+
+			if len(declrs) == 0 {
+				continue
+			}
+
+			pkg.Packages = declrs
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	return pkgs, nil
+}
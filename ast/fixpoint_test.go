@@ -0,0 +1,96 @@
+package ast_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+var fixpointSource = `package mock
+
+// User defines a user of the service.
+// @model
+type User struct{}
+`
+
+// TestParseUntilStable validates the regeneration of code for annotations within generated files.
+func TestParseUntilStable(t *testing.T) {
+	dir, pkgs := loadPackage(t, map[string]string{"go.mod": "module example.com/mock\n", "mock.go": fixpointSource})
+
+	model := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		content := fmt.Sprintf("package mock\n\n// %sJSON defines the JSON of %s.\n// @json\ntype %sJSON struct{}\n", str.Name, str.Name, str.Name)
+		return []gen.WriteDirective{{FileName: "user_model.go", Writer: gen.Text(content)}}, nil
+	}
+
+	json := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		content := fmt.Sprintf("package mock\n\n// %sMarshal defines the marshaller of %s.\ntype %sMarshal struct{}\n", str.Name, str.Name, str.Name)
+		return []gen.WriteDirective{{FileName: "user_json.go", Writer: gen.Text(content)}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("model", model)
+	registry.Register("json", json)
+
+	iterations, err := ast.ParseUntilStable(dir, metrics.New(), registry, true, ast.FixpointOptions{}, pkgs...)
+	if err != nil {
+		tests.Failed("Should have regenerated package until stable: %+q", err)
+	}
+	tests.Passed("Should have regenerated package until stable")
+
+	if iterations != 3 {
+		tests.Info("Iterations: %d", iterations)
+		tests.Failed("Should have stopped once generated files produced no new output")
+	}
+	tests.Passed("Should have stopped once generated files produced no new output")
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "user_json.go"))
+	if err != nil || !strings.Contains(string(content), "type UserJSONMarshal struct{}") {
+		tests.Info("Content: %s: %+v", content, err)
+		tests.Failed("Should have generated code for annotation within generated file")
+	}
+	tests.Passed("Should have generated code for annotation within generated file")
+}
+
+// TestParseUntilStableGuards validates the detection of generation cycles and the maximum iterations guard.
+func TestParseUntilStableGuards(t *testing.T) {
+	dir, pkgs := loadPackage(t, map[string]string{"go.mod": "module example.com/mock\n", "mock.go": fixpointSource})
+
+	var runs int
+	model := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		runs++
+		content := fmt.Sprintf("package mock\n\n// Model defines the generated model.\n// @model(version => %d)\ntype Model struct{}\n", runs%2)
+		return []gen.WriteDirective{{FileName: "model.go", Writer: gen.Text(content)}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("model", model)
+
+	_, err := ast.ParseUntilStable(dir, metrics.New(), registry, true, ast.FixpointOptions{}, pkgs...)
+	if err == nil || !strings.Contains(err.Error(), "Generation cycle detected") {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have detected generated file returning to earlier content")
+	}
+	tests.Passed("Should have detected generated file returning to earlier content")
+
+	growing := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		runs++
+		content := fmt.Sprintf("package mock\n\n// Model defines the generated model.\n// @model(version => %d)\ntype Model struct{}\n", runs)
+		return []gen.WriteDirective{{FileName: "model.go", Writer: gen.Text(content)}}, nil
+	}
+
+	registry.Register("model", growing)
+
+	iterations, err := ast.ParseUntilStable(dir, metrics.New(), registry, true, ast.FixpointOptions{MaxIterations: 4}, pkgs...)
+	if err == nil || iterations != 4 || !strings.Contains(err.Error(), "did not stabilise after 4 iterations") {
+		tests.Info("Iterations: %d: %+v", iterations, err)
+		tests.Failed("Should have stopped after maximum iterations")
+	}
+	tests.Passed("Should have stopped after maximum iterations")
+}
@@ -0,0 +1,116 @@
+package ast_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+var storeSource = `package mock
+
+import "io"
+
+// Store defines a store of readers.
+type Store interface {
+	Get(key, prefix string) (io.Reader, error)
+	Keys(prefixes ...string) (keys []string)
+	Set(string, []byte)
+}
+
+// DiskStore implements the Store on disk.
+type DiskStore struct{}
+
+// Get returns the reader for key.
+// @cache
+func (d *DiskStore) Get(key, prefix string) (io.Reader, error) {
+	return nil, nil
+}
+
+// Keys returns the keys with the prefixes.
+func (d DiskStore) Keys(prefixes ...string) (keys []string) {
+	return nil
+}
+
+func (d *DiskStore) Set(key string, data []byte) {}
+`
+
+// TestFunctionDefinitionMethod validates the generation of method stubs from the functions
+// of an interface.
+func TestFunctionDefinitionMethod(t *testing.T) {
+	expected := "\nfunc (s *storeMock) Get(key string, prefix string) (io.Reader, error) {\npanic(\"Get is not implemented\")\n}\n\nfunc (s *storeMock) Keys(prefixes ...string) (keys []string) {\npanic(\"Keys is not implemented\")\n}\n\nfunc (s *storeMock) Set(var1 string, var2 []byte) {\npanic(\"Set is not implemented\")\n}\n"
+
+	_, pkgs := loadPackage(t, map[string]string{"store.go": storeSource})
+
+	declr := pkgs[0].Packages[0]
+	if len(declr.Interfaces) != 1 {
+		tests.Failed("Should have found interface in package")
+	}
+
+	var methods []io.WriterTo
+	for _, def := range declr.Interfaces[0].Methods(&declr) {
+		methods = append(methods, def.Method(gen.PointerReceiver(gen.Name("s"), gen.Type("storeMock")), false))
+	}
+
+	if len(methods) != 3 {
+		tests.Info("Received: %d", len(methods))
+		tests.Failed("Should have retrieved all interface methods")
+	}
+	tests.Passed("Should have retrieved all interface methods")
+
+	var bu bytes.Buffer
+	if _, err := gen.Block(methods...).WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written method stubs: %+q.", err)
+	}
+	tests.Passed("Should have successfully written method stubs.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+		tests.Failed("Should have matched generated method stubs with expected.")
+	}
+	tests.Passed("Should have matched generated method stubs with expected.")
+}
+
+// TestInterfaceFrom validates the generation of a interface from the methods of a struct.
+func TestInterfaceFrom(t *testing.T) {
+	expected := "type StoreReader interface {\n\t// Get returns the reader for key.\n\tGet(key string, prefix string) (io.Reader, error)\n\t// Keys returns the keys with the prefixes.\n\tKeys(prefixes ...string) (keys []string)\n}\n"
+
+	_, pkgs := loadPackage(t, map[string]string{"store.go": storeSource})
+
+	methods, ok := pkgs[0].Packages[0].MethodFor("DiskStore")
+	if !ok || len(methods) != 3 {
+		tests.Info("Received: %d", len(methods))
+		tests.Failed("Should have found struct methods in package")
+	}
+	tests.Passed("Should have found struct methods in package")
+
+	var readers []ast.FuncDeclaration
+	for _, method := range methods {
+		if method.FuncName != "Set" {
+			readers = append(readers, method)
+		}
+	}
+
+	iface, err := ast.InterfaceFrom(gen.Name("StoreReader"), nil, false, readers...)
+	if err != nil {
+		tests.Failed("Should have successfully created interface: %+q", err)
+	}
+	tests.Passed("Should have successfully created interface")
+
+	var bu bytes.Buffer
+	if _, err := iface.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written interface: %+q.", err)
+	}
+	tests.Passed("Should have successfully written interface.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+		tests.Failed("Should have matched generated interface with expected.")
+	}
+	tests.Passed("Should have matched generated interface with expected.")
+}
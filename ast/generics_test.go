@@ -0,0 +1,105 @@
+package ast_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+)
+
+var genericSource = `package mock
+
+import "io"
+
+// Number defines the numeric constraint.
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// List defines a generic list.
+type List[T any] struct {
+	items []T
+}
+
+// Push adds the items to the list.
+func (l *List[T]) Push(items ...T) {}
+
+// Pairs maps keys to values.
+type Pairs[K comparable, V io.Reader] map[K]V
+
+// Names defines a list of names.
+type Names List[string]
+
+// Sum returns the sum of the values.
+func Sum[N Number](values []N, pairs map[string]Pairs[string, io.Reader]) N {
+	var sum N
+	return sum
+}
+`
+
+// TestGenericDeclarations validates the parsing of type parameters and instantiated generic types.
+func TestGenericDeclarations(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": genericSource})
+
+	declr := pkgs[0].Packages[0]
+
+	list, ok := declr.StructFor("List")
+	if !ok || len(list.TypeParams) != 1 || list.TypeParams[0].Name != "T" || list.TypeParams[0].Constraint != "any" {
+		tests.Info("Received: %+v", list.TypeParams)
+		tests.Failed("Should have parsed type parameters of struct")
+	}
+	tests.Passed("Should have parsed type parameters of struct")
+
+	var typeParams bytes.Buffer
+	if _, err := list.TypeParams.Declr().WriteTo(&typeParams); err != nil || typeParams.String() != "[T any]" || list.TypeParams.Instance("List").String() != "List[T]" {
+		tests.Info("Received: %q", typeParams.String())
+		tests.Failed("Should have generated type parameters of struct")
+	}
+	tests.Passed("Should have generated type parameters of struct")
+
+	methods, ok := declr.MethodFor("List")
+	if !ok || len(methods) != 1 || methods[0].RecieverPointer == nil || len(methods[0].RecieverTypeParams) != 1 || methods[0].RecieverTypeParams[0] != "T" {
+		tests.Info("Received: %+v", methods)
+		tests.Failed("Should have parsed method of generic struct")
+	}
+	tests.Passed("Should have parsed method of generic struct")
+
+	number, ok := declr.InterfaceFor("Number")
+	if !ok || !number.IsConstraint() || number.Constraints[0] != "~int | ~int64 | ~float64" {
+		tests.Info("Received: %+v", number.Constraints)
+		tests.Failed("Should have parsed type set of constraint interface")
+	}
+	tests.Passed("Should have parsed type set of constraint interface")
+
+	pairs, ok := declr.TypeFor("Pairs")
+	if !ok || len(pairs.TypeParams) != 2 || pairs.TypeParams[1].Constraint != "io.Reader" {
+		tests.Info("Received: %+v", pairs.TypeParams)
+		tests.Failed("Should have parsed type parameters of type")
+	}
+	tests.Passed("Should have parsed type parameters of type")
+
+	names, ok := declr.TypeFor("Names")
+	if !ok || names.TypeInfo == nil || names.TypeInfo.Type != "List[string]" || len(names.TypeInfo.TypeArgs) != 1 || names.TypeInfo.TypeArgs[0] != "string" {
+		tests.Info("Received: %+v", names.TypeInfo)
+		tests.Failed("Should have parsed instantiated generic type")
+	}
+	tests.Passed("Should have parsed instantiated generic type")
+
+	sum, ok := declr.FunctionFor("Sum")
+	if !ok || len(sum.TypeParams) != 1 || sum.TypeParams[0].Constraint != "Number" {
+		tests.Info("Received: %+v", sum.TypeParams)
+		tests.Failed("Should have parsed type parameters of function")
+	}
+	tests.Passed("Should have parsed type parameters of function")
+
+	def, err := sum.Definition(&declr)
+	if err != nil {
+		tests.Failed("Should have parsed function definition: %+q", err)
+	}
+
+	if len(def.Args) != 2 || def.Args[0].ExType != "[]N" || def.Args[1].Type != "map[string]Pairs[string, io.Reader]" || !def.Returns[0].TypeParam {
+		tests.Info("Received: %+v", def.Args)
+		tests.Failed("Should have parsed type parameters and instantiated types of arguments")
+	}
+	tests.Passed("Should have parsed type parameters and instantiated types of arguments")
+}
@@ -0,0 +1,45 @@
+package ast_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+)
+
+// writePackage writes the files, keyed by their path relative to it, into a temporary
+// directory removed once the test ends, returning the directory.
+func writePackage(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			tests.Failed("Should have created directory of file %q: %+q", name, err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			tests.Failed("Should have written file %q: %+q", name, err)
+		}
+	}
+
+	return dir
+}
+
+// loadPackage writes the files with writePackage, returning the directory and it's packages.
+func loadPackage(t *testing.T, files map[string]string) (string, ast.Packages) {
+	dir := writePackage(t, files)
+
+	pkgs, err := ast.FilteredPackageWithBuildCtx(metrics.New(), dir, build.Default)
+	if err != nil {
+		tests.Failed("Should have loaded package: %+q", err)
+	}
+	tests.Passed("Should have loaded package")
+
+	return dir, pkgs
+}
@@ -0,0 +1,190 @@
+package ast
+
+import (
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// Implementation defines a type which relates a struct to a interface it implements.
+type Implementation struct {
+	Struct    StructDeclaration
+	Interface InterfaceDeclaration
+
+	// Pointer is true if only a pointer to the struct implements the interface,
+	// due to methods declared with a pointer receiver.
+	Pointer bool
+}
+
+// ImplementationIndex defines a index of the interfaces implemented by the structs
+// found within a set of packages.
+type ImplementationIndex struct {
+	Implementations []Implementation
+	byInterface     map[string][]Implementation
+	byStruct        map[string][]Implementation
+}
+
+// Implementations returns a ImplementationIndex for all structs and interfaces declared
+// within the packages, excluding test packages. Type information is used if all the
+// packages were loaded with TypedPackageWithBuildCtx, else the method sets returned by
+// InterfaceDeclaration.Methods and StructDeclaration.Methods are matched by their signatures
+// for all of them. Generic structs and interfaces and constraint interfaces are not indexed,
+// nor are declarations the type checker failed to resolve when type information is used, or
+// structs whose method set can not be resolved otherwise.
+func (pkgs Packages) Implementations() *ImplementationIndex {
+	index := &ImplementationIndex{
+		byInterface: make(map[string][]Implementation),
+		byStruct:    make(map[string][]Implementation),
+	}
+
+	typed := pkgs.typeChecked()
+
+	var interfaces []InterfaceDeclaration
+	for _, pkg := range pkgs {
+		for _, declr := range pkg.Packages {
+			for _, inter := range declr.Interfaces {
+				if len(inter.TypeParams) != 0 || inter.IsConstraint() || (typed && inter.TypesType == nil) {
+					continue
+				}
+
+				interfaces = append(interfaces, inter)
+			}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		for _, declr := range pkg.Packages {
+			for _, str := range declr.Structs {
+				if len(str.TypeParams) != 0 || (typed && str.TypesType == nil) {
+					continue
+				}
+
+				var valueMethods, pointerMethods map[string]string
+				if !typed {
+					values, err := str.Methods(false)
+					if err != nil {
+						continue
+					}
+
+					pointers, err := str.Methods(true)
+					if err != nil {
+						continue
+					}
+
+					valueMethods, pointerMethods = signaturesOf(values), signaturesOf(pointers)
+				}
+
+				for _, inter := range interfaces {
+					var implements, pointer bool
+
+					if typed {
+						implements, pointer = typesImplements(str, inter)
+					} else {
+						methods := signaturesOf(inter.Methods(inter.Declr))
+						if implements = satisfies(valueMethods, methods); !implements {
+							implements, pointer = satisfies(pointerMethods, methods), true
+						}
+					}
+
+					if !implements {
+						continue
+					}
+
+					impl := Implementation{
+						Struct:    str,
+						Interface: inter,
+						Pointer:   pointer,
+					}
+
+					index.Implementations = append(index.Implementations, impl)
+					index.byStruct[declarationKey(str.FilePath, str.Name)] = append(index.byStruct[declarationKey(str.FilePath, str.Name)], impl)
+					index.byInterface[declarationKey(inter.FilePath, inter.Name)] = append(index.byInterface[declarationKey(inter.FilePath, inter.Name)], impl)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// typeChecked returns true/false if all packages were loaded with TypedPackageWithBuildCtx.
+func (pkgs Packages) typeChecked() bool {
+	for _, pkg := range pkgs {
+		if pkg.TypesPackage == nil {
+			return false
+		}
+	}
+
+	return len(pkgs) != 0
+}
+
+// ImplementorsOf returns the Implementations of the structs which implement the giving interface.
+func (ix *ImplementationIndex) ImplementorsOf(inter InterfaceDeclaration) []Implementation {
+	return ix.byInterface[declarationKey(inter.FilePath, inter.Name)]
+}
+
+// ImplementedBy returns the Implementations of the interfaces which the giving struct implements.
+func (ix *ImplementationIndex) ImplementedBy(str StructDeclaration) []Implementation {
+	return ix.byStruct[declarationKey(str.FilePath, str.Name)]
+}
+
+// declarationKey returns a key unique to a declaration of the giving name within the
+// package directory of the file.
+func declarationKey(filePath string, name string) string {
+	return filepath.Dir(filePath) + "#" + name
+}
+
+//===========================================================================================================
+
+// typesImplements returns true/false if the struct implements the interface as decided by
+// the type checker and if only a pointer to the struct does.
+func typesImplements(str StructDeclaration, inter InterfaceDeclaration) (bool, bool) {
+	iface, ok := inter.TypesType.Underlying().(*types.Interface)
+	if !ok {
+		return false, false
+	}
+
+	if types.Implements(str.TypesType, iface) {
+		return true, false
+	}
+
+	return types.Implements(types.NewPointer(str.TypesType), iface), true
+}
+
+// satisfies returns true/false if the method set contains all methods with the
+// same signatures.
+func satisfies(set map[string]string, methods map[string]string) bool {
+	for name, signature := range methods {
+		if set[name] != signature {
+			return false
+		}
+	}
+
+	return true
+}
+
+// signaturesOf returns the signatures of the functions by name.
+func signaturesOf(defs []FunctionDefinition) map[string]string {
+	signatures := make(map[string]string, len(defs))
+	for _, def := range defs {
+		signatures[def.Name] = signatureKey(def)
+	}
+
+	return signatures
+}
+
+// signatureKey returns the signature of the function with the types of it's arguments
+// and returns as seen from outside it's package, e.g `Read([]byte)(int,error)`.
+func signatureKey(def FunctionDefinition) string {
+	args := make([]string, 0, len(def.Args))
+	for _, arg := range def.Args {
+		args = append(args, arg.ExType)
+	}
+
+	returns := make([]string, 0, len(def.Returns))
+	for _, ret := range def.Returns {
+		returns = append(returns, ret.ExType)
+	}
+
+	return def.Name + "(" + strings.Join(args, ",") + ")(" + strings.Join(returns, ",") + ")"
+}
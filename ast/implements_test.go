@@ -0,0 +1,143 @@
+package ast_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+)
+
+var implementsInterfaces = `package mock
+
+// Reader defines a reader.
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+// ReadCloser defines a reader which can be closed.
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+// Named defines a type with a name.
+type Named interface {
+	Name() string
+}
+`
+
+var implementsStructs = `package mock
+
+// File defines a readable file.
+type File struct{}
+
+// Read reads from the file.
+func (f File) Read(b []byte) (int, error) { return 0, nil }
+
+// Close closes the file.
+func (f *File) Close() error { return nil }
+
+// Wrapper embeds a pointer to a file.
+type Wrapper struct {
+	*File
+}
+
+// Buffer embeds a file value.
+type Buffer struct {
+	File
+	Named
+}
+
+// Close shadows the promoted method with a different signature.
+func (b Buffer) Close() {}
+`
+
+var implementsCloser = `package other
+
+// Closer defines a type which can be closed.
+type Closer interface {
+	Close()
+}
+`
+
+// TestImplementations validates the discovery of interfaces implemented by structs.
+func TestImplementations(t *testing.T) {
+	dir, untyped := loadPackage(t, map[string]string{
+		"interfaces.go": implementsInterfaces,
+		"structs.go":    implementsStructs,
+	})
+
+	typed, err := ast.TypedPackageWithBuildCtx(metrics.New(), dir, build.Default)
+	if err != nil {
+		tests.Failed("Should have loaded package: %+q", err)
+	}
+
+	expected := map[string][]string{
+		"File":    {"ReadCloser*", "Reader"},
+		"Wrapper": {"ReadCloser", "Reader"},
+		"Buffer":  {"Named", "Reader"},
+	}
+
+	for mode, pkgs := range map[string]ast.Packages{"syntax": untyped, "types": typed} {
+		index := pkgs.Implementations()
+
+		for name, interfaces := range expected {
+			str, ok := pkgs[0].StructFor(name)
+			if !ok {
+				tests.Failed("Should have found struct %q", name)
+			}
+
+			var found []string
+			for _, impl := range index.ImplementedBy(str) {
+				if impl.Pointer {
+					found = append(found, impl.Interface.Name+"*")
+					continue
+				}
+
+				found = append(found, impl.Interface.Name)
+			}
+
+			sort.Strings(found)
+
+			if len(found) != len(interfaces) || (len(found) != 0 && found[0] != interfaces[0]) || (len(found) > 1 && found[1] != interfaces[1]) {
+				tests.Info("Mode: %s, Struct: %s, Found: %+q, Expected: %+q", mode, name, found, interfaces)
+				tests.Failed("Should have found interfaces implemented by struct")
+			}
+		}
+		tests.Passed("Should have found interfaces implemented by structs using %s", mode)
+
+		reader, ok := pkgs[0].InterfaceFor("Reader")
+		if !ok {
+			tests.Failed("Should have found Reader interface")
+		}
+
+		if implementors := index.ImplementorsOf(reader); len(implementors) != 3 {
+			tests.Info("Mode: %s, Implementors: %d", mode, len(implementors))
+			tests.Failed("Should have found all implementors of Reader")
+		}
+		tests.Passed("Should have found all implementors of Reader using %s", mode)
+	}
+
+	_, others := loadPackage(t, map[string]string{"closer.go": implementsCloser})
+
+	mixed := append(ast.Packages{}, typed...)
+	mixed = append(mixed, others...)
+
+	buffer, _ := typed[0].StructFor("Buffer")
+
+	var found []string
+	for _, impl := range mixed.Implementations().ImplementedBy(buffer) {
+		found = append(found, impl.Interface.Name)
+	}
+
+	sort.Strings(found)
+
+	if len(found) != 3 || found[0] != "Closer" {
+		tests.Info("Found: %+q", found)
+		tests.Failed("Should have matched typed and untyped packages by their signatures")
+	}
+	tests.Passed("Should have matched typed and untyped packages by their signatures")
+}
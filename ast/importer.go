@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	gobuild "go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/influx6/gobuild/build"
+)
+
+// buildImporter defines a types.ImporterFrom which type checks imported packages from the files
+// selected by the build.Context of the package loaded, so it's build tags, GOOS and GOARCH apply
+// to imports as they do to the package. Packages are resolved with go/build, which understands
+// `//go:build` constraints and modules, with cgo disabled as cgo files can not be type checked
+// without running cgo. Packages not found are resolved from the module of the importing
+// directory (see ImportDirFor).
+type buildImporter struct {
+	ctx        gobuild.Context
+	sizes      types.Sizes
+	tokenFiles *token.FileSet
+	packages   map[string]*types.Package
+}
+
+// newBuildImporter returns a buildImporter for the build.Context, whose files are parsed into
+// the token.FileSet.
+func newBuildImporter(ctx build.Context, tokenFiles *token.FileSet) *buildImporter {
+	importCtx := gobuild.Default
+	importCtx.GOOS = ctx.GOOS
+	importCtx.GOARCH = ctx.GOARCH
+	importCtx.BuildTags = ctx.BuildTags
+	importCtx.UseAllFiles = ctx.UseAllFiles
+	importCtx.InstallSuffix = ctx.InstallSuffix
+	importCtx.CgoEnabled = false
+
+	if ctx.GOROOT != "" {
+		importCtx.GOROOT = ctx.GOROOT
+	}
+
+	if ctx.GOPATH != "" {
+		importCtx.GOPATH = ctx.GOPATH
+	}
+
+	compiler := ctx.Compiler
+	if compiler == "" {
+		compiler = "gc"
+	}
+
+	sizes := types.SizesFor(compiler, importCtx.GOARCH)
+	if sizes == nil {
+		sizes = types.SizesFor("gc", "amd64")
+	}
+
+	return &buildImporter{
+		ctx:        importCtx,
+		sizes:      sizes,
+		tokenFiles: tokenFiles,
+		packages:   make(map[string]*types.Package),
+	}
+}
+
+// Import returns the package of the import path, resolved from the current directory.
+func (bi *buildImporter) Import(path string) (*types.Package, error) {
+	return bi.ImportFrom(path, ".", 0)
+}
+
+// ImportFrom returns the package of the import path as imported from the source directory,
+// type checking it once and returning the same package for following imports.
+func (bi *buildImporter) ImportFrom(path string, srcDir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	buildPkg, err := bi.ctx.Import(path, srcDir, 0)
+	if err != nil {
+		dir, ok := ImportDirFor(srcDir, path)
+		if !ok {
+			return nil, err
+		}
+
+		if buildPkg, err = bi.ctx.ImportDir(dir, 0); err != nil {
+			return nil, err
+		}
+
+		buildPkg.ImportPath = path
+	}
+
+	if pkg, ok := bi.packages[buildPkg.Dir]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through package %q", path)
+		}
+
+		return pkg, nil
+	}
+
+	// Marks the package as being checked, to report cycles instead of recursing.
+	bi.packages[buildPkg.Dir] = nil
+
+	files := make([]*ast.File, 0, len(buildPkg.GoFiles))
+	for _, name := range buildPkg.GoFiles {
+		file, err := parser.ParseFile(bi.tokenFiles, buildPkg.Dir+"/"+name, nil, 0)
+		if err != nil {
+			delete(bi.packages, buildPkg.Dir)
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	config := types.Config{
+		Importer:         bi,
+		Sizes:            bi.sizes,
+		FakeImportC:      true,
+		IgnoreFuncBodies: true,
+
+		// Errors within imports are not of the package being checked, so the types found
+		// are used as is.
+		Error: func(error) {},
+	}
+
+	pkg, err := config.Check(buildPkg.ImportPath, bi.tokenFiles, files, nil)
+	if pkg == nil {
+		delete(bi.packages, buildPkg.Dir)
+		return nil, err
+	}
+
+	bi.packages[buildPkg.Dir] = pkg
+	return pkg, nil
+}
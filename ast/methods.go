@@ -0,0 +1,377 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/gobuild/build"
+)
+
+// methodEntry defines a method within the method set of a type, Pointer is true if
+// the method is only within the method set of the pointer to the type.
+type methodEntry struct {
+	Def     FunctionDefinition
+	Pointer bool
+}
+
+// Methods returns the method set of the struct, which includes the methods promoted from
+// embedded fields, resolving embedded types declared in other files of the package and in
+// imported packages. The method set of *T is returned if pointer is true, which includes the
+// methods declared with a pointer receiver. A error is returned if the definition of a method
+// can not be resolved.
+func (str StructDeclaration) Methods(pointer bool) ([]FunctionDefinition, error) {
+	entries, err := typeMethods(str.Object, str.Declr, make(map[*ast.TypeSpec]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []FunctionDefinition
+	for _, entry := range entries {
+		if entry.Pointer && !pointer {
+			continue
+		}
+
+		defs = append(defs, entry.Def)
+	}
+
+	return defs, nil
+}
+
+// typeMethods returns the methods declared for the type of the giving spec, and if a struct,
+// the methods promoted from it's embedded fields. Methods declared closer to the type shadow
+// promoted methods of the same name.
+func typeMethods(spec *ast.TypeSpec, pkg *PackageDeclaration, visited map[*ast.TypeSpec]bool) ([]methodEntry, error) {
+	if spec == nil || pkg == nil || visited[spec] {
+		return nil, nil
+	}
+
+	visited[spec] = true
+	defer delete(visited, spec)
+
+	entries, err := pkg.declaredMethods(spec.Name.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return entries, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		seen[entry.Def.Name] = true
+	}
+
+	for _, field := range structType.Fields.List {
+		if len(field.Names) != 0 {
+			continue
+		}
+
+		fieldType, pointer := field.Type, false
+		if star, ok := fieldType.(*ast.StarExpr); ok {
+			fieldType, pointer = star.X, true
+		}
+
+		embeddedSpec, embeddedPkg, ok := pkg.resolveType(fieldType)
+		if !ok {
+			continue
+		}
+
+		var promoted []methodEntry
+		if intr, ok := embeddedSpec.Type.(*ast.InterfaceType); ok {
+			for _, def := range getInterfaceFunctions(intr, embeddedPkg, make(map[*ast.InterfaceType]bool)) {
+				promoted = append(promoted, methodEntry{Def: def})
+			}
+		} else if promoted, err = typeMethods(embeddedSpec, embeddedPkg, visited); err != nil {
+			return nil, err
+		}
+
+		for _, entry := range promoted {
+			if seen[entry.Def.Name] {
+				continue
+			}
+
+			seen[entry.Def.Name] = true
+
+			// Embedding a pointer promotes the methods with pointer receivers into
+			// the method set of the value.
+			entry.Pointer = entry.Pointer && !pointer
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// declaredMethods returns the methods declared with the giving receiver type name within
+// all files of the package, in order of their files. Each method is resolved against the
+// PackageDeclaration of the file it is declared in, so the imports of that file are used.
+func (pkg *PackageDeclaration) declaredMethods(typeName string) ([]methodEntry, error) {
+	var entries []methodEntry
+
+	files := pkg.packageFiles()
+	if len(files) == 0 {
+		for _, funcs := range pkg.ObjectFunc {
+			for _, fn := range funcs {
+				if fn.RecieverName != typeName {
+					continue
+				}
+
+				def, err := fn.Definition(fn.Declr)
+				if err != nil {
+					return nil, err
+				}
+
+				entries = append(entries, methodEntry{Def: def, Pointer: fn.RecieverPointer != nil})
+			}
+		}
+
+		return entries, nil
+	}
+
+	for _, file := range files {
+		for _, declr := range file.Decls {
+			fn, ok := declr.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+				continue
+			}
+
+			receiverType, pointer := fn.Recv.List[0].Type, false
+			if star, ok := receiverType.(*ast.StarExpr); ok {
+				receiverType, pointer = star.X, true
+			}
+
+			receiverType, _ = genericIndices(receiverType)
+			if ident, ok := receiverType.(*ast.Ident); !ok || ident.Name != typeName {
+				continue
+			}
+
+			fileDeclr := pkg.declarationAt(fn.Pos())
+
+			fun := FuncDeclaration{
+				FuncName:  fn.Name.Name,
+				FuncDeclr: fn,
+				Type:      fn.Type,
+				File:      fileDeclr.File,
+				Declr:     fileDeclr,
+			}
+
+			fun.TypesObject, fun.TypesType = fileDeclr.objectOf(fn.Name)
+
+			def, err := fun.Definition(fileDeclr)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to resolve method %s.%s: %s", typeName, fn.Name.Name, err)
+			}
+
+			entries = append(entries, methodEntry{Def: def, Pointer: pointer})
+		}
+	}
+
+	return entries, nil
+}
+
+// declarationAt returns the PackageDeclaration of the file of the package containing the position,
+// or the declaration itself if the file is not known.
+func (pkg *PackageDeclaration) declarationAt(pos token.Pos) *PackageDeclaration {
+	if pkg.scope == nil || pkg.tokenFiles == nil {
+		return pkg
+	}
+
+	if declr, ok := pkg.scope.declrs[pkg.tokenFiles.Position(pos).Filename]; ok {
+		return declr
+	}
+
+	return pkg
+}
+
+// packageFiles returns the files of the package the declaration belongs to, sorted by
+// their path.
+func (pkg *PackageDeclaration) packageFiles() []*ast.File {
+	if pkg.astPkg == nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(pkg.astPkg.Files))
+	for path := range pkg.astPkg.Files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	files := make([]*ast.File, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, pkg.astPkg.Files[path])
+	}
+
+	return files
+}
+
+// resolveType returns the TypeSpec for the giving type name or selector, which may be declared
+// in another file of the package or within a imported package, with the PackageDeclaration
+// of the file it was declared in.
+func (pkg *PackageDeclaration) resolveType(expr ast.Expr) (*ast.TypeSpec, *PackageDeclaration, bool) {
+	expr, _ = genericIndices(expr)
+
+	switch item := expr.(type) {
+	case *ast.Ident:
+		if item.Obj != nil {
+			if spec, ok := item.Obj.Decl.(*ast.TypeSpec); ok {
+				return spec, pkg.declarationAt(spec.Pos()), true
+			}
+		}
+
+		for _, file := range pkg.packageFiles() {
+			if obj := file.Scope.Lookup(item.Name); obj != nil {
+				if spec, ok := obj.Decl.(*ast.TypeSpec); ok {
+					return spec, pkg.declarationAt(spec.Pos()), true
+				}
+			}
+		}
+	case *ast.SelectorExpr:
+		pkgIdent, ok := item.X.(*ast.Ident)
+		if !ok {
+			return nil, nil, false
+		}
+
+		imported, ok := pkg.importedPackage(pkgIdent.Name)
+		if !ok {
+			return nil, nil, false
+		}
+
+		name := item.Sel.Name
+		for _, declr := range imported.Packages {
+			for _, elem := range declr.Interfaces {
+				if elem.Name == name {
+					return elem.Object, elem.Declr, true
+				}
+			}
+
+			for _, elem := range declr.Structs {
+				if elem.Name == name {
+					return elem.Object, elem.Declr, true
+				}
+			}
+
+			for _, elem := range declr.Types {
+				if elem.Name == name {
+					return elem.Object, elem.Declr, true
+				}
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// importedPackage returns the Package imported with the giving name. Imports not loaded
+// with the package, like those of the standard library, are loaded on first use with the
+// build.Context the package was loaded with, resolving their directory from the module of
+// the package first (see ImportDirFor).
+func (pkg *PackageDeclaration) importedPackage(pkgName string) (Package, bool) {
+	if imported, ok := pkg.ImportedPackageFor(pkgName); ok {
+		return imported, true
+	}
+
+	imp, ok := pkg.Imports[pkgName]
+	if !ok {
+		return Package{}, false
+	}
+
+	ctx := build.Default
+	if pkg.scope != nil {
+		ctx = pkg.scope.ctx
+	}
+
+	dir, ok := ImportDirFor(pkg.Dir, imp.Path)
+	if !ok {
+		buildPkg, err := ctx.Import(imp.Path, pkg.Dir, build.FindOnly)
+		if err != nil {
+			return Package{}, false
+		}
+
+		dir = buildPkg.Dir
+	}
+
+	// Keyed by the import path and build.Context alone, so the package is loaded once whatever
+	// name it is imported with.
+	key := fmt.Sprintf("%s#%s/%s/%s", imp.Path, ctx.GOOS, ctx.GOARCH, strings.Join(ctx.BuildTags, ","))
+
+	return loadProcessedPackage(key, func() (Package, bool) {
+		pkgs, err := FilteredPackageWithBuildCtx(metrics.New(), dir, ctx)
+		if err != nil {
+			return Package{}, false
+		}
+
+		for _, item := range pkgs {
+			if len(pkgs) > 1 && item.Name != filepath.Base(dir) {
+				continue
+			}
+
+			return item, true
+		}
+
+		return Package{}, false
+	})
+}
+
+// loadProcessedPackage returns the processed package of the key, loading and caching it if missing.
+// As generators may run concurrently, calls for a key being loaded wait for it instead of loading it again.
+func loadProcessedPackage(key string, load func() (Package, bool)) (Package, bool) {
+	processedPackages.pl.Lock()
+	for {
+		if res, ok := processedPackages.pkgs[key]; ok {
+			processedPackages.pl.Unlock()
+			return res, true
+		}
+
+		loading, ok := processedPackages.processing[key]
+		if !ok {
+			break
+		}
+
+		processedPackages.pl.Unlock()
+		<-loading
+		processedPackages.pl.Lock()
+	}
+
+	loading := make(chan struct{})
+	processedPackages.processing[key] = loading
+	processedPackages.pl.Unlock()
+
+	res, ok := load()
+
+	processedPackages.pl.Lock()
+	if ok {
+		processedPackages.pkgs[key] = res
+	}
+	delete(processedPackages.processing, key)
+	processedPackages.pl.Unlock()
+
+	close(loading)
+	return res, ok
+}
+
+// errorMethods returns the methods of the predeclared error interface.
+func errorMethods(pkg *PackageDeclaration) []FunctionDefinition {
+	expr, err := parser.ParseExpr("interface{ Error() string }")
+	if err != nil {
+		return nil
+	}
+
+	intr := expr.(*ast.InterfaceType)
+
+	def, err := GetFunctionDefinitionFromField(intr.Methods.List[0], pkg)
+	if err != nil {
+		return nil
+	}
+
+	def.Interface = intr
+	return []FunctionDefinition{def}
+}
@@ -0,0 +1,267 @@
+package ast_test
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+)
+
+var methodsShapes = `package shapes
+
+// Shape defines a shape with an area.
+type Shape interface {
+	Measured
+	Name() string
+}
+
+// Base defines a embeddable shape.
+type Base struct{}
+
+// Name returns the name of the shape.
+func (b Base) Name() string { return "" }
+
+// Scale scales the shape.
+func (b *Base) Scale(factor float64) {}
+`
+
+var methodsMeasured = `package shapes
+
+// Measured defines a type with an area.
+type Measured interface {
+	Area() float64
+}
+`
+
+var methodsStream = `package mock
+
+import (
+	"io"
+	"sync"
+
+	"example.com/mock/shapes"
+)
+
+// Stream defines a interface embedding local, imported and standard library interfaces.
+type Stream interface {
+	io.ReadCloser
+	shapes.Shape
+	Flusher
+	error
+}
+
+// Square defines a struct with promoted methods.
+type Square struct {
+	*shapes.Base
+	sync.Mutex
+	Flusher
+}
+
+// Area returns the area of the square.
+func (s Square) Area() float64 { return 0 }
+`
+
+var methodsFlusher = `package mock
+
+// Flusher defines a type which can be flushed.
+type Flusher interface {
+	Flush() error
+}
+`
+
+var methodsAliased = `package mock
+
+import stdio "io"
+
+// Source defines a struct embedding a interface of a package imported with a alias.
+type Source struct {
+	stdio.Reader
+}
+
+// Sink defines a interface embedding a interface of a package imported with a alias.
+type Sink interface {
+	stdio.Writer
+}
+`
+
+// TestMethodSets validates the flattening of embedded interfaces and promoted struct methods.
+func TestMethodSets(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{
+		"go.mod":                               "module example.com/mock\n",
+		"stream.go":                            methodsStream,
+		"flusher.go":                           methodsFlusher,
+		"aliased.go":                           methodsAliased,
+		filepath.Join("shapes", "shapes.go"):   methodsShapes,
+		filepath.Join("shapes", "measured.go"): methodsMeasured,
+	})
+
+	stream, ok := pkgs[0].InterfaceFor("Stream")
+	if !ok {
+		tests.Failed("Should have found Stream interface")
+	}
+
+	var names []string
+	for _, method := range stream.Methods(stream.Declr) {
+		names = append(names, method.Name)
+	}
+
+	if strings.Join(names, ",") != "Read,Close,Area,Name,Flush,Error" {
+		tests.Info("Methods: %+q", names)
+		tests.Failed("Should have flattened all embedded interfaces")
+	}
+	tests.Passed("Should have flattened all embedded interfaces")
+
+	square, ok := pkgs[0].StructFor("Square")
+	if !ok {
+		tests.Failed("Should have found Square struct")
+	}
+
+	valueMethods, err := square.Methods(false)
+	if err != nil {
+		tests.Failed("Should have resolved value method set: %+q", err)
+	}
+
+	names = names[:0]
+	for _, method := range valueMethods {
+		names = append(names, method.Name)
+	}
+
+	if strings.Join(names, ",") != "Area,Name,Scale,Flush" {
+		tests.Info("Methods: %+q", names)
+		tests.Failed("Should have promoted methods into value method set")
+	}
+	tests.Passed("Should have promoted methods into value method set")
+
+	pointerMethods, err := square.Methods(true)
+	if err != nil {
+		tests.Failed("Should have resolved pointer method set: %+q", err)
+	}
+
+	names = names[:0]
+	for _, method := range pointerMethods {
+		names = append(names, method.Name)
+	}
+
+	pointerSet := "," + strings.Join(names, ",") + ","
+	if !strings.HasPrefix(pointerSet, ",Area,Name,Scale,Lock,") || !strings.HasSuffix(pointerSet, ",Unlock,Flush,") {
+		tests.Info("Methods: %+q", names)
+		tests.Failed("Should have promoted methods into pointer method set")
+	}
+	tests.Passed("Should have promoted methods into pointer method set")
+
+	source, ok := pkgs[0].StructFor("Source")
+	if !ok {
+		tests.Failed("Should have found Source struct")
+	}
+
+	sink, ok := pkgs[0].InterfaceFor("Sink")
+	if !ok {
+		tests.Failed("Should have found Sink interface")
+	}
+
+	sourceMethods, err := source.Methods(false)
+	if err != nil {
+		tests.Failed("Should have resolved methods of Source: %+q", err)
+	}
+
+	sinkMethods := sink.Methods(sink.Declr)
+	if len(sourceMethods) != 1 || sourceMethods[0].Name != "Read" || len(sinkMethods) != 1 || sinkMethods[0].Name != "Write" {
+		tests.Failed("Should have resolved methods of package imported with a alias")
+	}
+	tests.Passed("Should have resolved methods of package imported with a alias")
+}
+
+var methodsWriter = `package files
+
+// Writer defines a struct whose methods are declared in another file.
+type Writer struct{}
+
+// Plain returns the name of the writer.
+func (w Writer) Plain() string { return "" }
+
+// ReadWriter defines a interface embedding a interface of another file.
+type ReadWriter interface {
+	Source
+	Close() error
+}
+`
+
+var methodsWriterMethods = `package files
+
+import "io"
+
+// Write writes to the writer.
+func (w *Writer) Write(dest io.Writer) error { return nil }
+
+// Close closes the writer.
+func (w *Writer) Close() error { return nil }
+`
+
+var methodsSource = `package files
+
+import "io"
+
+// Source defines a interface with a method using a import of it's own file.
+type Source interface {
+	From(r io.Reader) error
+}
+`
+
+// TestMethodSetsAcrossFiles validates the resolution of methods and embedded types using imports
+// of the file they are declared in only.
+func TestMethodSetsAcrossFiles(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{
+		"writer.go":  methodsWriter,
+		"methods.go": methodsWriterMethods,
+		"source.go":  methodsSource,
+	})
+
+	writer, ok := pkgs[0].StructFor("Writer")
+	if !ok {
+		tests.Failed("Should have found Writer struct")
+	}
+
+	methods, err := writer.Methods(true)
+	if err != nil {
+		tests.Failed("Should have resolved methods of Writer: %+q", err)
+	}
+
+	var names []string
+	for _, method := range methods {
+		names = append(names, method.Name)
+	}
+
+	sort.Strings(names)
+
+	if strings.Join(names, ",") != "Close,Plain,Write" {
+		tests.Info("Methods: %+q", names)
+		tests.Failed("Should have resolved methods using imports of their file")
+	}
+	tests.Passed("Should have resolved methods using imports of their file")
+
+	for _, method := range methods {
+		if method.Name == "Write" && (len(method.Args) != 1 || method.Args[0].ExType != "io.Writer") {
+			tests.Info("Args: %+v", method.Args)
+			tests.Failed("Should have resolved argument type from import of method file")
+		}
+	}
+	tests.Passed("Should have resolved argument type from import of method file")
+
+	readWriter, ok := pkgs[0].InterfaceFor("ReadWriter")
+	if !ok {
+		tests.Failed("Should have found ReadWriter interface")
+	}
+
+	names = names[:0]
+	for _, method := range readWriter.Methods(readWriter.Declr) {
+		names = append(names, method.Name)
+	}
+
+	if strings.Join(names, ",") != "From,Close" {
+		tests.Info("Methods: %+q", names)
+		tests.Failed("Should have flattened embedded interface using imports of it's file")
+	}
+	tests.Passed("Should have flattened embedded interface using imports of it's file")
+}
@@ -0,0 +1,413 @@
+package ast
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	goModFile = "go.mod"
+	vendorDir = "vendor"
+)
+
+var (
+	// ErrNoModule defines a error returned when no go.mod file is found for a giving path.
+	ErrNoModule = errors.New("No go.mod found within path or any of it's parent directories")
+
+	// loadedModules caches all go.mod files already read, keyed by module root directory.
+	loadedModules = struct {
+		ml   sync.Mutex
+		mods map[string]Module
+	}{
+		mods: make(map[string]Module),
+	}
+)
+
+// ModuleReplace defines a type which holds a `replace` directive found in a go.mod file.
+type ModuleReplace struct {
+	Path       string
+	Version    string
+	NewPath    string
+	NewVersion string
+}
+
+// IsLocal returns true/false if the replacement points to a directory on the file system
+// rather than another module version.
+func (mr ModuleReplace) IsLocal() bool {
+	return strings.HasPrefix(mr.NewPath, "./") || strings.HasPrefix(mr.NewPath, "../") || filepath.IsAbs(mr.NewPath)
+}
+
+// Module defines a type which holds details about a go module as described by the
+// go.mod file found at it's root directory.
+type Module struct {
+	Path     string
+	Dir      string
+	GoMod    string
+	Requires map[string]string
+	Replaces []ModuleReplace
+}
+
+// ImportPathFor returns the import path for the giving directory if it is within the
+// module. Directories within the module's vendor directory will have the path of the
+// vendored package returned.
+func (m Module) ImportPathFor(dir string) (string, error) {
+	rel, err := filepath.Rel(m.Dir, dir)
+	if err != nil {
+		return "", err
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("Path %q is not within module %q", dir, m.Path)
+	}
+
+	if rel == "." {
+		return m.Path, nil
+	}
+
+	if strings.HasPrefix(rel, vendorDir+"/") {
+		return strings.TrimPrefix(rel, vendorDir+"/"), nil
+	}
+
+	return path.Join(m.Path, rel), nil
+}
+
+// DirFor returns the directory for the giving import path as seen from within the module.
+// It resolves paths within the module itself, `replace` directives, the module's vendor
+// directory and finally the module cache for required modules.
+func (m Module) DirFor(importPath string) (string, bool) {
+	if rest, ok := trimModulePath(importPath, m.Path); ok {
+		return existingDir(filepath.Join(m.Dir, filepath.FromSlash(rest)))
+	}
+
+	var replaced *ModuleReplace
+	var replacedRest string
+	for index, replace := range m.Replaces {
+		rest, ok := trimModulePath(importPath, replace.Path)
+		if !ok {
+			continue
+		}
+
+		if replaced == nil || len(replace.Path) > len(replaced.Path) {
+			replaced = &m.Replaces[index]
+			replacedRest = rest
+		}
+	}
+
+	if replaced != nil {
+		if replaced.IsLocal() {
+			root := replaced.NewPath
+			if !filepath.IsAbs(root) {
+				root = filepath.Join(m.Dir, root)
+			}
+
+			return existingDir(filepath.Join(root, filepath.FromSlash(replacedRest)))
+		}
+
+		if replaced.NewVersion != "" {
+			return moduleCacheDir(replaced.NewPath, replaced.NewVersion, replacedRest)
+		}
+	}
+
+	if dir, ok := existingDir(filepath.Join(m.Dir, vendorDir, filepath.FromSlash(importPath))); ok {
+		return dir, true
+	}
+
+	var required, requiredRest string
+	for modPath := range m.Requires {
+		rest, ok := trimModulePath(importPath, modPath)
+		if !ok {
+			continue
+		}
+
+		if len(modPath) > len(required) {
+			required = modPath
+			requiredRest = rest
+		}
+	}
+
+	if required != "" {
+		return moduleCacheDir(required, m.Requires[required], requiredRest)
+	}
+
+	return "", false
+}
+
+// ModuleFor returns the Module which contains the giving directory by searching
+// for a go.mod file within the directory and it's parents. Directories need
+// not exist, which allows destination paths to be resolved.
+func ModuleFor(dir string) (Module, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Module{}, err
+	}
+
+	for {
+		modFile := filepath.Join(dir, goModFile)
+		if stat, err := os.Stat(modFile); err == nil && !stat.IsDir() {
+			return readModule(dir, modFile)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Module{}, ErrNoModule
+		}
+
+		dir = parent
+	}
+}
+
+// ImportPathFor returns the import path of the giving directory. It uses the module
+// containing the directory if any, else it falls back to the directory's location
+// within the GOPATH.
+func ImportPathFor(dir string) (string, error) {
+	if mod, err := ModuleFor(dir); err == nil {
+		return mod.ImportPathFor(dir)
+	}
+
+	if rel, ok := withinGoSrc(dir); ok {
+		return filepath.ToSlash(rel), nil
+	}
+
+	return "", fmt.Errorf("Path %q is not within a go module or current GOPATH", dir)
+}
+
+// ImportDirFor returns the directory of the giving import path as seen from the provided
+// directory, which is used to find the module in use. It falls back to the GOPATH
+// if no module is found or the module can not resolve the import path.
+func ImportDirFor(fromDir string, importPath string) (string, bool) {
+	if mod, err := ModuleFor(fromDir); err == nil {
+		if dir, ok := mod.DirFor(importPath); ok {
+			return dir, true
+		}
+	}
+
+	return existingDir(filepath.Join(goSrcPath, filepath.FromSlash(importPath)))
+}
+
+// readModule returns the Module for the giving go.mod file, using the cache of
+// already read modules where possible.
+func readModule(dir string, modFile string) (Module, error) {
+	loadedModules.ml.Lock()
+	mod, ok := loadedModules.mods[dir]
+	loadedModules.ml.Unlock()
+
+	if ok {
+		return mod, nil
+	}
+
+	content, err := readSource(modFile)
+	if err != nil {
+		return Module{}, err
+	}
+
+	mod, err = ParseModuleFile(dir, content)
+	if err != nil {
+		return Module{}, fmt.Errorf("%s: %s", modFile, err.Error())
+	}
+
+	mod.GoMod = modFile
+
+	loadedModules.ml.Lock()
+	loadedModules.mods[dir] = mod
+	loadedModules.ml.Unlock()
+
+	return mod, nil
+}
+
+// ParseModuleFile returns a Module from the content of a go.mod file, with dir as the
+// module's root directory. Only the module, require and replace directives are read.
+func ParseModuleFile(dir string, content []byte) (Module, error) {
+	mod := Module{
+		Dir:      dir,
+		Requires: make(map[string]string),
+	}
+
+	var block string
+	var lineNumber int
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Text()
+		if index := strings.Index(line, "//"); index != -1 {
+			line = line[:index]
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if block != "" {
+			if line == ")" {
+				block = ""
+				continue
+			}
+
+			if err := mod.addDirective(block, line); err != nil {
+				return mod, fmt.Errorf("%d: %s", lineNumber, err.Error())
+			}
+			continue
+		}
+
+		verb := line
+		rest := ""
+		if index := strings.IndexFunc(line, unicode.IsSpace); index != -1 {
+			verb, rest = line[:index], strings.TrimSpace(line[index:])
+		}
+
+		if rest == "(" {
+			block = verb
+			continue
+		}
+
+		if err := mod.addDirective(verb, rest); err != nil {
+			return mod, fmt.Errorf("%d: %s", lineNumber, err.Error())
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return mod, err
+	}
+
+	if mod.Path == "" {
+		return mod, errors.New("No module directive found")
+	}
+
+	return mod, nil
+}
+
+func (m *Module) addDirective(verb string, line string) error {
+	fields := strings.Fields(line)
+	for index, field := range fields {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			fields[index] = unquoted
+		}
+	}
+
+	switch verb {
+	case "module":
+		if len(fields) != 1 {
+			return errors.New("Module directive expects a single path")
+		}
+
+		m.Path = fields[0]
+	case "require":
+		if len(fields) < 2 {
+			return errors.New("Require directive expects a path and version")
+		}
+
+		m.Requires[fields[0]] = fields[1]
+	case "replace":
+		arrow := -1
+		for index, field := range fields {
+			if field == "=>" {
+				arrow = index
+				break
+			}
+		}
+
+		if arrow < 1 || arrow == len(fields)-1 {
+			return errors.New("Replace directive expects `path [version] => path [version]`")
+		}
+
+		var replace ModuleReplace
+		replace.Path = fields[0]
+		if arrow > 1 {
+			replace.Version = fields[1]
+		}
+
+		replace.NewPath = fields[arrow+1]
+		if len(fields) > arrow+2 {
+			replace.NewVersion = fields[arrow+2]
+		}
+
+		m.Replaces = append(m.Replaces, replace)
+	}
+
+	return nil
+}
+
+// moduleCacheDir returns the directory for the package within the module cache.
+func moduleCacheDir(modPath string, version string, rest string) (string, bool) {
+	cacheDir := os.Getenv("GOMODCACHE")
+	if cacheDir == "" {
+		root := strings.Split(goPath, string(filepath.ListSeparator))[0]
+		if root == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", false
+			}
+
+			root = filepath.Join(home, "go")
+		}
+
+		cacheDir = filepath.Join(root, "pkg", "mod")
+	}
+
+	modDir := filepath.Join(cacheDir, filepath.FromSlash(escapeModulePath(modPath))+"@"+escapeModulePath(version))
+	return existingDir(filepath.Join(modDir, filepath.FromSlash(rest)))
+}
+
+// escapeModulePath escapes upper case letters the way the module cache expects.
+func escapeModulePath(modPath string) string {
+	var escaped strings.Builder
+	for _, r := range modPath {
+		if unicode.IsUpper(r) {
+			escaped.WriteRune('!')
+			escaped.WriteRune(unicode.ToLower(r))
+			continue
+		}
+
+		escaped.WriteRune(r)
+	}
+
+	return escaped.String()
+}
+
+// trimModulePath returns the remaining part of the import path if it's within the
+// giving module path.
+func trimModulePath(importPath string, modPath string) (string, bool) {
+	if importPath == modPath {
+		return "", true
+	}
+
+	if strings.HasPrefix(importPath, modPath+"/") {
+		return strings.TrimPrefix(importPath, modPath+"/"), true
+	}
+
+	return "", false
+}
+
+// withinGoSrc returns the path relative to the GOPATH source directory if it's within it.
+func withinGoSrc(dir string) (string, bool) {
+	if goPath == "" {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(goSrcPath, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return rel, true
+}
+
+func existingDir(dir string) (string, bool) {
+	if stat, err := os.Stat(dir); err == nil && stat.IsDir() {
+		return dir, true
+	}
+
+	return "", false
+}
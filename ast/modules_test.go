@@ -0,0 +1,120 @@
+package ast_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/moz/ast"
+)
+
+var goModContent = `
+module github.com/example/project // the module path
+
+go 1.12
+
+require (
+	github.com/influx6/faux v0.0.1
+	"github.com/influx6/gobuild" v0.0.2
+)
+
+require github.com/icrowley/fake v0.0.3
+
+replace github.com/influx6/faux => ./local/faux
+replace (
+	github.com/influx6/gobuild v0.0.2 => github.com/influx6/gobuild v0.0.5
+)
+`
+
+// TestParseModuleFile validates the reading of module, require and replace directives from a go.mod file.
+func TestParseModuleFile(t *testing.T) {
+	mod, err := ast.ParseModuleFile("/project", []byte(goModContent))
+	if err != nil {
+		tests.Failed("Should have successfully parsed go.mod content: %+q", err)
+	}
+	tests.Passed("Should have successfully parsed go.mod content")
+
+	if mod.Path != "github.com/example/project" {
+		tests.Info("Received: %q", mod.Path)
+		tests.Failed("Should have read module path")
+	}
+	tests.Passed("Should have read module path")
+
+	if len(mod.Requires) != 3 || mod.Requires["github.com/influx6/gobuild"] != "v0.0.2" {
+		tests.Info("Received: %+v", mod.Requires)
+		tests.Failed("Should have read all required modules")
+	}
+	tests.Passed("Should have read all required modules")
+
+	if len(mod.Replaces) != 2 {
+		tests.Info("Received: %+v", mod.Replaces)
+		tests.Failed("Should have read all replace directives")
+	}
+	tests.Passed("Should have read all replace directives")
+
+	if !mod.Replaces[0].IsLocal() || mod.Replaces[1].IsLocal() || mod.Replaces[1].NewVersion != "v0.0.5" {
+		tests.Info("Received: %+v", mod.Replaces)
+		tests.Failed("Should have read replacement paths and versions")
+	}
+	tests.Passed("Should have read replacement paths and versions")
+}
+
+// TestModuleResolution validates the resolution of import paths and directories from a go.mod root.
+func TestModuleResolution(t *testing.T) {
+	root := writePackage(t, map[string]string{
+		"go.mod":                             goModContent,
+		filepath.Join("models", "models.go"): "package models\n",
+		filepath.Join("local", "faux", "metrics", "metrics.go"):              "package metrics\n",
+		filepath.Join("vendor", "github.com", "corpix", "uarand", "rand.go"): "package uarand\n",
+	})
+
+	dirs := []string{
+		filepath.Join(root, "models"),
+		filepath.Join(root, "local", "faux", "metrics"),
+		filepath.Join(root, "vendor", "github.com", "corpix", "uarand"),
+	}
+
+	importPath, err := ast.ImportPathFor(filepath.Join(root, "models"))
+	if err != nil || importPath != "github.com/example/project/models" {
+		tests.Info("Received: %q: %+v", importPath, err)
+		tests.Failed("Should have resolved import path from module root")
+	}
+	tests.Passed("Should have resolved import path from module root")
+
+	importPath, err = ast.ImportPathFor(filepath.Join(root, "generated", "mocks"))
+	if err != nil || importPath != "github.com/example/project/generated/mocks" {
+		tests.Info("Received: %q: %+v", importPath, err)
+		tests.Failed("Should have resolved import path for non-existing destination")
+	}
+	tests.Passed("Should have resolved import path for non-existing destination")
+
+	importPath, err = ast.ImportPathFor(filepath.Join(root, "vendor", "github.com", "corpix", "uarand"))
+	if err != nil || importPath != "github.com/corpix/uarand" {
+		tests.Info("Received: %q: %+v", importPath, err)
+		tests.Failed("Should have resolved vendored import path")
+	}
+	tests.Passed("Should have resolved vendored import path")
+
+	if dir, ok := ast.ImportDirFor(root, "github.com/example/project/models"); !ok || dir != dirs[0] {
+		tests.Info("Received: %q", dir)
+		tests.Failed("Should have resolved directory of package within module")
+	}
+	tests.Passed("Should have resolved directory of package within module")
+
+	if dir, ok := ast.ImportDirFor(root, "github.com/influx6/faux/metrics"); !ok || dir != dirs[1] {
+		tests.Info("Received: %q", dir)
+		tests.Failed("Should have resolved directory of replaced package")
+	}
+	tests.Passed("Should have resolved directory of replaced package")
+
+	if dir, ok := ast.ImportDirFor(root, "github.com/corpix/uarand"); !ok || dir != dirs[2] {
+		tests.Info("Received: %q", dir)
+		tests.Failed("Should have resolved directory of vendored package")
+	}
+	tests.Passed("Should have resolved directory of vendored package")
+
+	if _, ok := ast.ImportDirFor(root, "fmt"); ok {
+		tests.Failed("Should have failed to resolve standard library package")
+	}
+	tests.Passed("Should have failed to resolve standard library package")
+}
@@ -1,12 +1,12 @@
 package ast
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"go/token"
 	"io"
-	"regexp"
-	"strconv"
+	"io/ioutil"
 	"strings"
 )
 
@@ -18,6 +18,32 @@ type AnnotationDeclaration struct {
 	Params    map[string]string      `json:"params"`
 	Attrs     map[string]interface{} `json:"attrs"`
 	Defer     bool                   `json:"defer"`
+
+	// ArgValues contains the parsed value of each argument, the value of a `key => value`
+	// argument is it's value. ParamValues contains the parsed values of Params.
+	ArgValues   []AnnotationValue          `json:"argValues"`
+	ParamValues map[string]AnnotationValue `json:"paramValues"`
+
+	// Position is the position of the annotation within the source file, or within the
+	// text read by ReadAnnotationsFromCommentry.
+	Position token.Position `json:"position"`
+
+	// Association is the resolved association declared for the annotation with `@associates`
+	// on the declaration, set by the AnnotationRegistry before invoking it's generator.
+	Association *AnnotationAssociationDeclaration `json:"-"`
+
+	// Emitted contains the directives emitted before a deferred annotation is generated, set
+	// by the AnnotationRegistry for annotations marked with `defer => true`.
+	Emitted []AnnotationWriteDirective `json:"-"`
+}
+
+// String returns the annotation as declared, without it's template, e.g `@mongo(collection => users)`.
+func (ad AnnotationDeclaration) String() string {
+	if len(ad.Arguments) == 0 {
+		return ad.Name
+	}
+
+	return ad.Name + "(" + strings.Join(ad.Arguments, ", ") + ")"
 }
 
 // HasArg returns true/false if the giving AnnotationDeclaration has a giving key in its Arguments.
@@ -41,17 +67,148 @@ func (ad AnnotationDeclaration) Attr(name string) interface{} {
 	return ad.Attrs[name]
 }
 
+// Value returns the parsed value of the param with giving key ("name"). Params without a
+// parsed value, like those of a AnnotationDeclaration created by hand, are parsed on access.
+// The key is matched with it's first letter in either case, e.g `defer` and `Defer`.
+func (ad AnnotationDeclaration) Value(name string) (AnnotationValue, bool) {
+	for _, key := range paramKeys(name) {
+		if value, ok := ad.ParamValues[key]; ok {
+			return value, true
+		}
+
+		if raw, ok := ad.Params[key]; ok {
+			return rawValue(raw), true
+		}
+	}
+
+	return AnnotationValue{}, false
+}
+
+// ArgValue returns the parsed value of the argument at giving index.
+func (ad AnnotationDeclaration) ArgValue(index int) (AnnotationValue, bool) {
+	if index < len(ad.ArgValues) {
+		return ad.ArgValues[index], true
+	}
+
+	if index < len(ad.Arguments) {
+		return rawValue(ad.Arguments[index]), true
+	}
+
+	return AnnotationValue{}, false
+}
+
+// StringParam returns the string value of the param with giving key ("name"), with
+// quoted strings decoded.
+func (ad AnnotationDeclaration) StringParam(name string) (string, bool) {
+	value, ok := ad.Value(name)
+	return value.Text, ok
+}
+
+// IntParam returns the integer value of the param with giving key ("name").
+func (ad AnnotationDeclaration) IntParam(name string) (int64, error) {
+	value, err := ad.mustValue(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return value.Int()
+}
+
+// FloatParam returns the float value of the param with giving key ("name").
+func (ad AnnotationDeclaration) FloatParam(name string) (float64, error) {
+	value, err := ad.mustValue(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return value.Float()
+}
+
+// BoolParam returns the boolean value of the param with giving key ("name").
+func (ad AnnotationDeclaration) BoolParam(name string) (bool, error) {
+	value, err := ad.mustValue(name)
+	if err != nil {
+		return false, err
+	}
+
+	return value.Bool()
+}
+
+// ListParam returns the items of the list value of the param with giving key ("name"), a
+// param which is not a list is returned as a list of itself.
+func (ad AnnotationDeclaration) ListParam(name string) ([]AnnotationValue, error) {
+	value, err := ad.mustValue(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.List(), nil
+}
+
+// MapParam returns the entries of the map value of the param with giving key ("name").
+func (ad AnnotationDeclaration) MapParam(name string) (map[string]AnnotationValue, error) {
+	value, err := ad.mustValue(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Map()
+}
+
+func (ad AnnotationDeclaration) mustValue(name string) (AnnotationValue, error) {
+	value, ok := ad.Value(name)
+	if !ok {
+		return value, fmt.Errorf("Param %q not found in annotation %s", name, ad.Name)
+	}
+
+	return value, nil
+}
+
+func (ad AnnotationDeclaration) hasParam(name string) bool {
+	_, ok := ad.Params[name]
+	return ok
+}
+
+// paramKeys returns the name of a param and the name with the case of it's first letter swapped.
+func paramKeys(name string) []string {
+	if name == "" {
+		return []string{name}
+	}
+
+	swapped := strings.ToUpper(name[:1]) + name[1:]
+	if swapped == name {
+		swapped = strings.ToLower(name[:1]) + name[1:]
+	}
+
+	if swapped == name {
+		return []string{name}
+	}
+
+	return []string{name, swapped}
+}
+
 // ReadAnnotationsFromCommentry returns a slice of all annotation passed from the provided list.
 func ReadAnnotationsFromCommentry(r io.Reader) []AnnotationDeclaration {
+	annotations, _ := ReadAnnotationsWithDiagnostics(r)
+	return annotations
+}
+
+// ReadAnnotationsWithDiagnostics returns a slice of all annotation passed from the provided list,
+// with the diagnostics of malformed annotations. Positions are relative to the provided text,
+// use ReadAnnotationsFromComments to position them within a source file.
+//
+// Arguments are parsed as values (see AnnotationValue), which can be quoted, lists or maps and
+// can span multiple lines when a line ends with a "\", a "," or a opened list, map or string.
+// A argument list ending with "{" starts a template which ends at a line starting with "})".
+func ReadAnnotationsWithDiagnostics(r io.Reader) ([]AnnotationDeclaration, Diagnostics) {
 	var annotations []AnnotationDeclaration
+	var diagnostics Diagnostics
 
-	reader := bufio.NewReader(r)
+	content, _ := ioutil.ReadAll(r)
+	lines := strings.SplitAfter(string(content), "\n")
 
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			break
-		}
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
 
 		trimmedline := string(cleanWord([]byte(line)))
 		if trimmedline == "" {
@@ -63,119 +220,210 @@ func ReadAnnotationsFromCommentry(r io.Reader) []AnnotationDeclaration {
 			continue
 		}
 
+		position := token.Position{Line: index + 1, Column: strings.IndexRune(line, '@') + 1}
+
 		params := make(map[string]string, 0)
 
 		if !strings.Contains(trimmedline, "(") {
-			annotations = append(annotations, AnnotationDeclaration{Name: trimmedline, Params: params, Attrs: make(map[string]interface{})})
+			annotations = append(annotations, AnnotationDeclaration{Name: trimmedline, Params: params, Attrs: make(map[string]interface{}), Position: position})
 			continue
 		}
 
 		argIndex := strings.IndexRune(trimmedline, '(')
 		argName := trimmedline[:argIndex]
-		argContents := trimmedline[argIndex:]
-
-		// Do we have a template associated with this annotation, if not, split the
-		// commas and let those be our arguments.
-		if !strings.HasSuffix(argContents, "{") {
-
-			argContents = strings.TrimPrefix(strings.TrimSuffix(argContents, ")"), "(")
+		argContents := trimmedline[argIndex+1:]
+
+		annotation := AnnotationDeclaration{
+			Name:        argName,
+			Params:      params,
+			ParamValues: make(map[string]AnnotationValue),
+			Attrs:       make(map[string]interface{}),
+			Position:    position,
+		}
 
-			var parts []string
+		diag := func(severity Severity, message string, args ...interface{}) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity:   severity,
+				Position:   position,
+				Annotation: argName,
+				Message:    fmt.Sprintf(message, args...),
+			})
+		}
 
-			for _, part := range strings.Split(argContents, ",") {
-				trimmed := strings.TrimSpace(part)
-				if trimmed == "" {
-					continue
+		// readDefer finds out if the annotation is to be deferred.
+		readDefer := func() {
+			if value, ok := annotation.Value("defer"); ok {
+				deferred, err := value.Bool()
+				if err != nil {
+					diag(SeverityWarning, "invalid defer param %q", value.Raw)
 				}
 
-				parts = append(parts, trimmed)
+				annotation.Defer = deferred
+			}
+		}
 
-				// If we are dealing with key value pairs then split, trimspace and set
-				// in params. We only expect 2 values, any more and we wont consider the rest.
-				if kvPieces := strings.Split(trimmed, "=>"); len(kvPieces) > 1 {
-					val := strings.TrimSpace(kvPieces[1])
-					params[strings.TrimSpace(kvPieces[0])] = val
-				}
+		// Do we have a template associated with this annotation, if so, the arguments end
+		// at the "{" which starts the template.
+		if strings.HasSuffix(argContents, "{") {
+			templateArgs := argContents[:len(argContents)-1]
+
+			args, err := parseArguments(templateArgs)
+			if err != nil && err != errUnclosed {
+				diag(SeverityWarning, "invalid arguments: %s", err)
+				args = splitArguments(templateArgs)
 			}
 
-			var deferred bool
+			annotation.setArguments(args)
+			readDefer()
 
-			// Find out if we are to be deferred
-			defered, ok := params["defer"]
-			if !ok {
-				defered = params["Defer"]
+			template, read, closed := readTemplate(lines[index+1:])
+			index += read
+
+			if !closed {
+				diag(SeverityError, "template is not closed with })")
 			}
 
-			deferred, _ = strconv.ParseBool(defered)
+			annotation.Template = strings.TrimSpace(template)
+
+			if annotation.HasArg("asJSON") || annotation.hasParam("asJSON") {
+				var attrs map[string]interface{}
+				if err := json.Unmarshal([]byte(annotation.Template), &attrs); err == nil {
+					annotation.Template = ""
+				} else {
+					diagnostics = append(diagnostics, Diagnostic{
+						Severity:   SeverityError,
+						Position:   position,
+						Annotation: argName,
+						Message:    fmt.Sprintf("invalid JSON template: %s", err),
+						Err:        err,
+					})
+				}
 
-			annotations = append(annotations, AnnotationDeclaration{
-				Arguments: parts,
-				Name:      argName,
-				Params:    params,
-				Defer:     deferred,
-				Attrs:     make(map[string]interface{}),
-			})
+				annotation.Attrs = attrs
+			}
 
+			annotations = append(annotations, annotation)
 			continue
 		}
 
-		templateIndex := strings.IndexRune(argContents, '{')
-		templateArgs := argContents[:templateIndex]
-		templateArgs = strings.TrimPrefix(strings.TrimSuffix(templateArgs, ")"), "(")
+		var args []annotationArgument
+		var err error
 
-		var parts []string
+		for {
+			explicit := strings.HasSuffix(argContents, "\\")
+			argContents = strings.TrimSuffix(argContents, "\\")
 
-		for _, part := range strings.Split(templateArgs, ",") {
-			trimmed := strings.TrimSpace(part)
-			if trimmed == "" {
-				continue
+			args, err = parseArguments(argContents)
+			if index+1 >= len(lines) || !continues(argContents, lines[index+1], explicit, err) {
+				break
 			}
 
-			parts = append(parts, trimmed)
+			index++
+			argContents += " " + string(cleanWord([]byte(lines[index])))
+		}
 
-			// If we are dealing with key value pairs then split, trimspace and set
-			// in params. We only expect 2 values, any more and we wont consider the rest.
-			if kvPieces := strings.Split(trimmed, "=>"); len(kvPieces) > 1 {
-				val := strings.TrimSpace(kvPieces[1])
-				params[strings.TrimSpace(kvPieces[0])] = val
+		switch err {
+		case nil:
+		case errUnclosed:
+			diag(SeverityWarning, `missing closing ")"`)
+		default:
+			diag(SeverityWarning, "invalid arguments: %s", err)
+			args = splitArguments(argContents)
+		}
+
+		for _, arg := range args {
+			if arg.Named && strings.TrimSpace(arg.Key) == "" {
+				diag(SeverityWarning, "param %q has no name", arg.Value.Raw)
 			}
 		}
 
-		template := strings.TrimSpace(readTemplate(reader))
+		annotation.setArguments(args)
+		readDefer()
 
-		var asJSON bool
-		for _, item := range parts {
-			if item == "asJSON" {
-				asJSON = true
-				break
-			}
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations, diagnostics
+}
+
+// setArguments sets the arguments and params of the annotation from the parsed arguments.
+func (ad *AnnotationDeclaration) setArguments(args []annotationArgument) {
+	for _, arg := range args {
+		ad.Arguments = append(ad.Arguments, arg.Raw)
+		ad.ArgValues = append(ad.ArgValues, arg.Value)
+
+		if arg.Named {
+			ad.Params[arg.Key] = arg.Value.Raw
+			ad.ParamValues[arg.Key] = arg.Value
+		}
+	}
+}
+
+// continues returns true/false if the arguments of a annotation continue on the next line, which
+// is the case if the line ends with a "\", a opened list, map or string, or if the arguments are
+// not closed and either end with "(", ",", "=>" or ":" or the next line starts with ")".
+func continues(argContents string, next string, explicit bool, err error) bool {
+	nextLine := string(cleanWord([]byte(next)))
+
+	switch {
+	case explicit:
+		return true
+	case nextLine == "" || strings.HasPrefix(nextLine, "@"):
+		return false
+	case err == errIncomplete:
+		return true
+	case err != errUnclosed:
+		return false
+	}
+
+	trimmed := strings.TrimSpace(argContents)
+	if trimmed == "" || strings.HasPrefix(nextLine, ")") {
+		return true
+	}
+
+	for _, suffix := range []string{",", "=>", ":"} {
+		if strings.HasSuffix(trimmed, suffix) {
+			return true
 		}
+	}
 
-		if _, ok := params["asJSON"]; ok {
-			asJSON = true
+	return false
+}
+
+// splitArguments returns the arguments by splitting the giving text on commas and "=>", as read
+// before arguments were parsed as values. It is used for arguments which fail to parse.
+func splitArguments(argContents string) []annotationArgument {
+	var args []annotationArgument
+
+	for _, part := range strings.Split(strings.TrimSuffix(strings.TrimSpace(argContents), ")"), ",") {
+		trimmed := strings.TrimSpace(part)
+		if trimmed == "" {
+			continue
 		}
 
-		var attrs map[string]interface{}
+		arg := annotationArgument{Raw: trimmed, Value: rawValue(trimmed)}
 
-		if asJSON {
-			if err := json.Unmarshal([]byte(template), &attrs); err == nil {
-				template = ""
-			}
-		} else {
-			attrs = make(map[string]interface{})
+		// If we are dealing with key value pairs then split, trimspace and set
+		// in params. We only expect 2 values, any more and we wont consider the rest.
+		if kvPieces := strings.Split(trimmed, "=>"); len(kvPieces) > 1 {
+			arg.Named = true
+			arg.Key = strings.TrimSpace(kvPieces[0])
+			arg.Value = rawValue(strings.TrimSpace(kvPieces[1]))
 		}
 
-		annotations = append(annotations, AnnotationDeclaration{
-			Arguments: parts,
-			Name:      argName,
-			Template:  template,
-			Params:    params,
-			Attrs:     attrs,
-		})
+		args = append(args, arg)
+	}
 
+	return args
+}
+
+// rawValue returns the giving text parsed as a AnnotationValue, or as a word if it fails to parse.
+func rawValue(raw string) AnnotationValue {
+	if value, err := parseValueText(raw); err == nil {
+		return value
 	}
 
-	return annotations
+	return AnnotationValue{Kind: IdentValue, Raw: raw, Text: raw}
 }
 
 var ending = []byte("})")
@@ -184,49 +432,21 @@ var empty = []byte("")
 var singleComment = []byte("//")
 var multiComment = []byte("/*")
 var multiCommentItem = []byte("*")
-var commentry = regexp.MustCompile(`\s*?([\/\/*|\*|\/]+)`)
 
-func readTemplate(reader *bufio.Reader) string {
+// readTemplate reads the template of a annotation from the giving lines until it's closing `})`,
+// returning the template, the number of lines read and true/false if the closing was found.
+func readTemplate(lines []string) (string, int, bool) {
 	var bu bytes.Buffer
 
-	var seenEnd bool
-
-	for {
-		// Do we have another pending prefix, if so, we are at the ending, so return.
-		if seenEnd {
-			data, _ := reader.Peek(100)
-			dataVal := commentry.ReplaceAllString(string(data), "")
-			dataVal = string(cleanWord([]byte(dataVal)))
-
-			// fmt.Printf("Peek2: %+q -> %+q\n", dataVal, data)
-
-			if strings.HasPrefix(string(dataVal), "@") {
-				return bu.String()
-			}
-
-			// If it's all space, then return.
-			// if strings.TrimSpace(string(dataVal)) == "" {
-			return bu.String()
-			// }
-		}
-
-		twoWord, err := reader.ReadString('\n')
-		if err != nil {
-			bu.WriteString(twoWord)
-			return bu.String()
-		}
-
-		twoWorded := cleanWord([]byte(twoWord))
-		// fmt.Printf("Ending: %+q -> %t\n", twoWorded, bytes.HasPrefix(twoWorded, ending))
-
-		if bytes.HasPrefix(twoWorded, ending) {
-			seenEnd = true
-			continue
+	for index, line := range lines {
+		if bytes.HasPrefix(cleanWord([]byte(line)), ending) {
+			return bu.String(), index + 1, true
 		}
 
-		bu.WriteString(twoWord)
+		bu.WriteString(line)
 	}
 
+	return bu.String(), len(lines), false
 }
 
 func cleanWord(word []byte) []byte {
@@ -1,8 +1,11 @@
 package ast
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"go/token"
+	"sort"
 	"strings"
 	"sync"
 
@@ -39,15 +42,31 @@ type InterfaceAnnotationGenerator func(string, AnnotationDeclaration, InterfaceD
 // All generators are expected to return
 type PackageAnnotationGenerator func(string, AnnotationDeclaration, PackageDeclaration, Package) ([]gen.WriteDirective, error)
 
+// VariableAnnotationGenerator defines a function which generates specific code related to the giving
+// Annotation for a package level variable or constant declaration. This allows you to generate new
+// sources from annotated values, like route tables or default configuration.
+// It is responsible to fully contain all operations required to both generator any source and write such to.
+type VariableAnnotationGenerator func(string, AnnotationDeclaration, VariableDeclaration, PackageDeclaration, Package) ([]gen.WriteDirective, error)
+
 //===========================================================================================================
 
 // Annotations defines a struct which contains a map of all annotation code generator.
+// The context maps contain the same generators as the others, taking a GeneratorContext.
 type Annotations struct {
 	Types      map[string]TypeAnnotationGenerator
 	Structs    map[string]StructAnnotationGenerator
 	Functions  map[string]FunctionAnnotationGenerator
 	Packages   map[string]PackageAnnotationGenerator
 	Interfaces map[string]InterfaceAnnotationGenerator
+	Variables  map[string]VariableAnnotationGenerator
+	Schemas    map[string]AnnotationSchema
+
+	TypeContexts      map[string]TypeContextGenerator
+	StructContexts    map[string]StructContextGenerator
+	FunctionContexts  map[string]FunctionContextGenerator
+	PackageContexts   map[string]PackageContextGenerator
+	InterfaceContexts map[string]InterfaceContextGenerator
+	VariableContexts  map[string]VariableContextGenerator
 }
 
 // AnnotationRegistry defines a structure which contains giving list of possible
@@ -55,34 +74,34 @@ type Annotations struct {
 type AnnotationRegistry struct {
 	metrics              metrics.Metrics
 	ml                   sync.RWMutex
-	typeAnnotations      map[string]TypeAnnotationGenerator
-	structAnnotations    map[string]StructAnnotationGenerator
-	pkgAnnotations       map[string]PackageAnnotationGenerator
-	interfaceAnnotations map[string]InterfaceAnnotationGenerator
-	functionAnnotations  map[string]FunctionAnnotationGenerator
+	typeAnnotations      map[string]TypeContextGenerator
+	structAnnotations    map[string]StructContextGenerator
+	pkgAnnotations       map[string]PackageContextGenerator
+	interfaceAnnotations map[string]InterfaceContextGenerator
+	functionAnnotations  map[string]FunctionContextGenerator
+	variableAnnotations  map[string]VariableContextGenerator
+	schemas              map[string]AnnotationSchema
+	strict               bool
+	allowed              map[string]bool
 }
 
 // NewAnnotationRegistry returns a new instance of a AnnotationRegistry.
 func NewAnnotationRegistry() *AnnotationRegistry {
-	return &AnnotationRegistry{
-		metrics:              metrics.New(),
-		typeAnnotations:      make(map[string]TypeAnnotationGenerator),
-		structAnnotations:    make(map[string]StructAnnotationGenerator),
-		pkgAnnotations:       make(map[string]PackageAnnotationGenerator),
-		interfaceAnnotations: make(map[string]InterfaceAnnotationGenerator),
-		functionAnnotations:  make(map[string]FunctionAnnotationGenerator),
-	}
+	return NewAnnotationRegistryWith(metrics.New())
 }
 
 // NewAnnotationRegistryWith returns a new instance of a AnnotationRegistry.
 func NewAnnotationRegistryWith(log metrics.Metrics) *AnnotationRegistry {
 	return &AnnotationRegistry{
 		metrics:              log,
-		typeAnnotations:      make(map[string]TypeAnnotationGenerator),
-		structAnnotations:    make(map[string]StructAnnotationGenerator),
-		pkgAnnotations:       make(map[string]PackageAnnotationGenerator),
-		interfaceAnnotations: make(map[string]InterfaceAnnotationGenerator),
-		functionAnnotations:  make(map[string]FunctionAnnotationGenerator),
+		typeAnnotations:      make(map[string]TypeContextGenerator),
+		structAnnotations:    make(map[string]StructContextGenerator),
+		pkgAnnotations:       make(map[string]PackageContextGenerator),
+		interfaceAnnotations: make(map[string]InterfaceContextGenerator),
+		functionAnnotations:  make(map[string]FunctionContextGenerator),
+		variableAnnotations:  make(map[string]VariableContextGenerator),
+		schemas:              make(map[string]AnnotationSchema),
+		allowed:              make(map[string]bool),
 	}
 }
 
@@ -98,25 +117,50 @@ func (a *AnnotationRegistry) Clone() Annotations {
 	cloned.Packages = make(map[string]PackageAnnotationGenerator)
 	cloned.Interfaces = make(map[string]InterfaceAnnotationGenerator)
 	cloned.Functions = make(map[string]FunctionAnnotationGenerator)
+	cloned.Variables = make(map[string]VariableAnnotationGenerator)
+	cloned.Schemas = make(map[string]AnnotationSchema)
+
+	cloned.TypeContexts = make(map[string]TypeContextGenerator)
+	cloned.StructContexts = make(map[string]StructContextGenerator)
+	cloned.PackageContexts = make(map[string]PackageContextGenerator)
+	cloned.InterfaceContexts = make(map[string]InterfaceContextGenerator)
+	cloned.FunctionContexts = make(map[string]FunctionContextGenerator)
+	cloned.VariableContexts = make(map[string]VariableContextGenerator)
+
+	ctx := context.Background()
 
 	for name, item := range a.pkgAnnotations {
-		cloned.Packages[name] = item
+		cloned.Packages[name] = item.PositionalWith(ctx, a.metrics)
+		cloned.PackageContexts[name] = item
 	}
 
 	for name, item := range a.functionAnnotations {
-		cloned.Functions[name] = item
+		cloned.Functions[name] = item.PositionalWith(ctx, a.metrics)
+		cloned.FunctionContexts[name] = item
 	}
 
 	for name, item := range a.structAnnotations {
-		cloned.Structs[name] = item
+		cloned.Structs[name] = item.PositionalWith(ctx, a.metrics)
+		cloned.StructContexts[name] = item
 	}
 
 	for name, item := range a.typeAnnotations {
-		cloned.Types[name] = item
+		cloned.Types[name] = item.PositionalWith(ctx, a.metrics)
+		cloned.TypeContexts[name] = item
 	}
 
 	for name, item := range a.interfaceAnnotations {
-		cloned.Interfaces[name] = item
+		cloned.Interfaces[name] = item.PositionalWith(ctx, a.metrics)
+		cloned.InterfaceContexts[name] = item
+	}
+
+	for name, item := range a.variableAnnotations {
+		cloned.Variables[name] = item.PositionalWith(ctx, a.metrics)
+		cloned.VariableContexts[name] = item
+	}
+
+	for name, item := range a.schemas {
+		cloned.Schemas[name] = item
 	}
 
 	return cloned
@@ -140,7 +184,7 @@ func (a *AnnotationRegistry) Copy(registry *AnnotationRegistry, strategy CopyStr
 	a.ml.Lock()
 	defer a.ml.Unlock()
 
-	for name, item := range cloned.Packages {
+	for name, item := range cloned.PackageContexts {
 		_, ok := a.pkgAnnotations[name]
 
 		if !ok || (ok && strategy == TheirsOverOurs) {
@@ -148,33 +192,47 @@ func (a *AnnotationRegistry) Copy(registry *AnnotationRegistry, strategy CopyStr
 		}
 	}
 
-	for name, item := range cloned.Functions {
+	for name, item := range cloned.FunctionContexts {
 		_, ok := a.functionAnnotations[name]
 		if !ok || (ok && strategy == TheirsOverOurs) {
 			a.functionAnnotations[name] = item
 		}
 	}
 
-	for name, item := range cloned.Types {
+	for name, item := range cloned.TypeContexts {
 		_, ok := a.typeAnnotations[name]
 		if !ok || (ok && strategy == TheirsOverOurs) {
 			a.typeAnnotations[name] = item
 		}
 	}
 
-	for name, item := range cloned.Structs {
+	for name, item := range cloned.StructContexts {
 		_, ok := a.structAnnotations[name]
 		if !ok || (ok && strategy == TheirsOverOurs) {
 			a.structAnnotations[name] = item
 		}
 	}
 
-	for name, item := range cloned.Interfaces {
+	for name, item := range cloned.InterfaceContexts {
 		_, ok := a.interfaceAnnotations[name]
 		if !ok || (ok && strategy == TheirsOverOurs) {
 			a.interfaceAnnotations[name] = item
 		}
 	}
+
+	for name, item := range cloned.VariableContexts {
+		_, ok := a.variableAnnotations[name]
+		if !ok || (ok && strategy == TheirsOverOurs) {
+			a.variableAnnotations[name] = item
+		}
+	}
+
+	for name, item := range cloned.Schemas {
+		_, ok := a.schemas[name]
+		if !ok || (ok && strategy == TheirsOverOurs) {
+			a.schemas[name] = item
+		}
+	}
 }
 
 // MustPackage returns the annotation generator associated with the giving annotation name.
@@ -188,253 +246,444 @@ func (a *AnnotationRegistry) MustPackage(annotation string) PackageAnnotationGen
 }
 
 // AnnotationWriteDirective defines a type which provides a WriteDiretive and the associated
-// name.
+// name, with the path of the file the annotation was found in.
 type AnnotationWriteDirective struct {
 	gen.WriteDirective
 	Annotation string
+	Source     string
 }
 
 // ParseDeclr runs the generators suited for each declaration and type returning a slice of
 // Annotationgen.WriteDirective that delivers the content to be created for each piece.
+// Annotations with a registered schema are validated and default-filled before their generator is
+// invoked (see RegisterSchema), violations are returned as Diagnostics. The association declared for
+// a annotation with `@associates` is resolved and set as it's Association, a error is returned if the
+// associated type can not be found (see ResolveAssociation).
+// The error of a generator is returned as a Diagnostic positioned at it's annotation. In strict mode,
+// annotations without a generator for their declaration are returned as Diagnostics (see SetStrict).
+// Annotations marked with `defer => true` are run after all others, with the directives emitted by
+// the others within the declaration as their Emitted (see ParsePackages).
 func (a *AnnotationRegistry) ParseDeclr(pkg Package, declr PackageDeclaration, toDir string) ([]AnnotationWriteDirective, error) {
-	var directives []AnnotationWriteDirective
+	run := newGeneration(ParseConfig{}, a.metrics, Packages{pkg}, toDir)
+
+	directives, err := a.parseDeclr(run, pkg, declr, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deferred, err := a.parseDeclr(run, pkg, declr, true, directives)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(directives, deferred...), nil
+}
+
+// ParsePackages runs the generators of all declarations within the packages in two phases, first
+// those of the annotations of all packages which are not deferred, then those of annotations marked
+// with `defer => true`, which receive all directives emitted by the first phase as their Emitted. This
+// allows aggregate generators, like registries, routers or index files, to see what per-type generators
+// produced. Associations are resolved across the packages beforehand (see Packages.ResolveAssociations).
+func (a *AnnotationRegistry) ParsePackages(pkgs Packages, toDir string) ([]AnnotationWriteDirective, error) {
+	return a.ParsePackagesWith(ParseConfig{}, pkgs, toDir)
+}
+
+// ParsePackagesWith runs the generators of all declarations within the packages as done by ParsePackages,
+// with the context and options of the config provided to generators through their GeneratorContext. The
+// generators of up to config.Workers declarations run concurrently, while directives are returned in the
+// order of the declarations. The run stops with the error of the context once done, or on the first error
+// of a generator unless config.KeepGoing is set, which runs all generators and returns the failures as
+// GenerationErrors with the directives of the successful generators, or none if config.AllOrNothing.
+func (a *AnnotationRegistry) ParsePackagesWith(config ParseConfig, pkgs Packages, toDir string) ([]AnnotationWriteDirective, error) {
+	pkgs.ResolveAssociations()
+
+	run := newGeneration(config, a.metrics, pkgs, toDir)
+
+	var failures GenerationErrors
+
+	directives, err := a.parsePhase(run, false, nil)
+	if failed, ok := err.(GenerationErrors); ok {
+		failures, err = append(failures, failed...), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	emitted := directives[:len(directives):len(directives)]
+
+	deferred, err := a.parsePhase(run, true, emitted)
+	if failed, ok := err.(GenerationErrors); ok {
+		failures, err = append(failures, failed...), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	directives = append(directives, deferred...)
+
+	if len(failures) == 0 {
+		return directives, nil
+	}
+
+	a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
+		metrics.With("error", failures.Summary()),
+		metrics.With("Failures", len(failures)),
+		metrics.With("Directive", len(directives)),
+		metrics.With("AllOrNothing", run.allOrNothing))
+
+	if run.allOrNothing {
+		return nil, failures
+	}
+
+	return directives, failures
+}
+
+// parsePhase runs the generators of the deferred or non-deferred annotations of all declarations of
+// the generation, with the declarations spread across it's workers. Directives are returned in the
+// order of the declarations, regardless of the order they complete in. Declarations yet to run are
+// skipped once a generator fails, as the context provided to generators is cancelled, unless keeping
+// going, where the failures of all declarations are returned as GenerationErrors with the directives.
+func (a *AnnotationRegistry) parsePhase(run generation, deferred bool, emitted []AnnotationWriteDirective) ([]AnnotationWriteDirective, error) {
+	type declrJob struct {
+		pkg   Package
+		declr PackageDeclaration
+	}
+
+	var jobs []declrJob
+	for _, pkg := range run.pkgs {
+		for _, declr := range pkg.Packages {
+			jobs = append(jobs, declrJob{pkg: pkg, declr: declr})
+		}
+	}
+
+	// Declarations are ordered by package and file, so directives do not depend on the
+	// order packages were loaded or provided in.
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].pkg.Path != jobs[j].pkg.Path {
+			return jobs[i].pkg.Path < jobs[j].pkg.Path
+		}
+
+		return jobs[i].declr.FilePath < jobs[j].declr.FilePath
+	})
+
+	results := make([][]AnnotationWriteDirective, len(jobs))
+	errs := make([]error, len(jobs))
+
+	parent := run.ctx
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	run.ctx = ctx
+
+	workers := run.workers
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[index] = err
+					continue
+				}
+
+				results[index], errs[index] = a.parseDeclr(run, jobs[index].pkg, jobs[index].declr, deferred, emitted)
+				if errs[index] != nil && !run.keepGoing {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for index := range jobs {
+		indexes <- index
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
 
-	// Generate directives for package level
-	for _, annotation := range declr.Annotations {
-		a.metrics.Emit(metrics.Info("Directive Generation"),
-			metrics.With("Level", "Package"), metrics.With("Annotaton", annotation.Name), metrics.With("Params", annotation.Params), metrics.With("Arguments", annotation.Arguments), metrics.With("Template", annotation.Template))
+	var failures GenerationErrors
 
-		generator, err := a.GetPackage(annotation.Name)
-		if err != nil {
+	// Declarations skipped after a failure have the error of the cancelled context, the
+	// failure is returned instead.
+	for _, err := range errs {
+		if failed, ok := err.(GenerationErrors); ok && run.keepGoing {
+			failures = append(failures, failed...)
 			continue
 		}
 
-		drs, err := generator(toDir, annotation, declr, pkg)
-		if err != nil {
-			a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-				metrics.With("error", err), metrics.With("Level", "Package"), metrics.With("Annotaton", annotation.Name), metrics.With("Params", annotation.Params), metrics.With("Arguments", annotation.Arguments), metrics.With("Template", annotation.Template))
+		if err != nil && err != context.Canceled {
 			return nil, err
 		}
+	}
 
-		a.metrics.Emit(metrics.Info("Directive Generation: Success"),
-			metrics.With("Level", "Package"),
-			metrics.With("Directive", len(drs)),
-			metrics.With("Annotaton", annotation.Name),
-			metrics.With("Params", annotation.Params),
-			metrics.With("Arguments", annotation.Arguments),
-			metrics.With("Template", annotation.Template))
-
-		for _, directive := range drs {
-			directives = append(directives, AnnotationWriteDirective{
-				WriteDirective: directive,
-				Annotation:     annotation.Name,
-			})
-		}
+	var directives []AnnotationWriteDirective
+	for _, drs := range results {
+		directives = append(directives, drs...)
 	}
 
+	if len(failures) != 0 {
+		return directives, failures
+	}
+
+	return directives, nil
+}
+
+// annotatedDeclaration defines a declaration of a package whose annotations are generated for,
+// with the generator registered for a annotation of it's kind.
+type annotatedDeclaration struct {
+	kind         string
+	level        string
+	name         string
+	location     token.Position
+	annotations  []AnnotationDeclaration
+	associations map[string]AnnotationAssociationDeclaration
+	generator    func(annotation string) (PackageContextGenerator, error)
+}
+
+// annotatedDeclarations returns the package and each interface, struct, function, type and
+// variable of the declaration, in that order, as annotatedDeclarations.
+func (a *AnnotationRegistry) annotatedDeclarations(declr PackageDeclaration) []annotatedDeclaration {
+	declrs := []annotatedDeclaration{{
+		kind:        packageKind,
+		level:       "Package",
+		name:        declr.Package,
+		location:    token.Position{Filename: declr.FilePath},
+		annotations: declr.Annotations,
+		generator:   a.GetPackageContext,
+	}}
+
 	for _, inter := range declr.Interfaces {
-		for _, annotation := range inter.Annotations {
-			a.metrics.Emit(metrics.Info("Directive Generation"),
-				metrics.With("Level", "Interface"),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Interface", inter.Object.Name.Name),
-				metrics.With("Params", annotation.Params),
-				metrics.With("Arguments", annotation.Arguments),
-				metrics.With("Template", annotation.Template))
+		inter := inter
+		declrs = append(declrs, annotatedDeclaration{
+			kind:         interfaceKind,
+			level:        "Interface",
+			name:         inter.Name,
+			location:     inter.Location,
+			annotations:  inter.Annotations,
+			associations: inter.Associations,
+			generator: func(annotation string) (PackageContextGenerator, error) {
+				generator, err := a.GetInterfaceContext(annotation)
+				if err != nil {
+					return nil, err
+				}
+
+				return func(ctx GeneratorContext) ([]gen.WriteDirective, error) {
+					return generator(ctx, inter)
+				}, nil
+			},
+		})
+	}
 
-			generator, err := a.GetInterfaceType(annotation.Name)
-			if err != nil {
-				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-					metrics.With("error", err),
-					metrics.With("Level", "Interface"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Interface", inter.Object.Name.Name),
-					metrics.With("Params", annotation.Params),
-					metrics.With("Arguments", annotation.Arguments),
-					metrics.With("Template", annotation.Template))
-				continue
-			}
+	for _, str := range declr.Structs {
+		str := str
+		declrs = append(declrs, annotatedDeclaration{
+			kind:         structKind,
+			level:        "Struct",
+			name:         str.Name,
+			location:     str.Location,
+			annotations:  str.Annotations,
+			associations: str.Associations,
+			generator: func(annotation string) (PackageContextGenerator, error) {
+				generator, err := a.GetStructContext(annotation)
+				if err != nil {
+					return nil, err
+				}
+
+				return func(ctx GeneratorContext) ([]gen.WriteDirective, error) {
+					return generator(ctx, str)
+				}, nil
+			},
+		})
+	}
 
-			drs, err := generator(toDir, annotation, inter, declr, pkg)
-			if err != nil {
-				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-					metrics.With("error", err),
-					metrics.With("Level", "Interface"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Interface", inter.Object.Name.Name),
-					metrics.With("Params", annotation.Params),
-					metrics.With("Arguments", annotation.Arguments),
-					metrics.With("Template", annotation.Template))
-				return nil, err
-			}
+	for _, fn := range declr.Functions {
+		fn := fn
+		declrs = append(declrs, annotatedDeclaration{
+			kind:         functionKind,
+			level:        "Function",
+			name:         fn.FuncName,
+			location:     fn.Location,
+			annotations:  fn.Annotations,
+			associations: fn.Associations,
+			generator: func(annotation string) (PackageContextGenerator, error) {
+				generator, err := a.GetFunctionContext(annotation)
+				if err != nil {
+					return nil, err
+				}
+
+				return func(ctx GeneratorContext) ([]gen.WriteDirective, error) {
+					return generator(ctx, fn)
+				}, nil
+			},
+		})
+	}
 
-			a.metrics.Emit(metrics.Info("Directive Generation: Success"),
-				metrics.With("Level", "Interface"),
-				metrics.With("Directive", len(drs)),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("nterface", inter.Object.Name.Name),
-				metrics.With("Params", annotation.Params),
-				metrics.With("Arguments", annotation.Arguments),
-				metrics.With("Template", annotation.Template))
+	for _, typ := range declr.Types {
+		typ := typ
+		declrs = append(declrs, annotatedDeclaration{
+			kind:         typeKind,
+			level:        "Type",
+			name:         typ.Name,
+			location:     typ.Location,
+			annotations:  typ.Annotations,
+			associations: typ.Associations,
+			generator: func(annotation string) (PackageContextGenerator, error) {
+				generator, err := a.GetTypeContext(annotation)
+				if err != nil {
+					return nil, err
+				}
+
+				return func(ctx GeneratorContext) ([]gen.WriteDirective, error) {
+					return generator(ctx, typ)
+				}, nil
+			},
+		})
+	}
 
-			for _, directive := range drs {
-				directives = append(directives, AnnotationWriteDirective{
-					WriteDirective: directive,
-					Annotation:     annotation.Name,
-				})
-			}
-		}
+	for _, variable := range declr.Variables {
+		variable := variable
+		declrs = append(declrs, annotatedDeclaration{
+			kind:         variableKind,
+			level:        "Variable",
+			name:         variable.Name,
+			location:     variable.Location,
+			annotations:  variable.Annotations,
+			associations: variable.Associations,
+			generator: func(annotation string) (PackageContextGenerator, error) {
+				generator, err := a.GetVariableContext(annotation)
+				if err != nil {
+					return nil, err
+				}
+
+				return func(ctx GeneratorContext) ([]gen.WriteDirective, error) {
+					return generator(ctx, variable)
+				}, nil
+			},
+		})
 	}
 
-	for _, structs := range declr.Structs {
-		for _, annotation := range structs.Annotations {
-			a.metrics.Emit(metrics.Info("Directive Generation"),
-				metrics.With("Level", "Struct"),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Struct", structs.Object.Name.Name),
-				metrics.With("Params", annotation.Params),
-				metrics.With("Arguments", annotation.Arguments),
-				metrics.With("Template", annotation.Template))
+	return declrs
+}
 
-			generator, err := a.GetStructType(annotation.Name)
-			if err != nil {
-				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-					metrics.With("error", err),
-					metrics.With("Level", "Struct"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Struct", structs.Object.Name.Name),
-					metrics.With("Params", annotation.Params),
-					metrics.With("Arguments", annotation.Arguments),
-					metrics.With("Template", annotation.Template))
-				continue
-			}
+// parseDeclr runs the generators of the annotations of the declaration which are deferred or not
+// within the generation, deferred annotations receive the emitted directives. When keeping going,
+// the directives of successful generators are returned with the GenerationErrors of the others.
+func (a *AnnotationRegistry) parseDeclr(run generation, pkg Package, declr PackageDeclaration, deferred bool, emitted []AnnotationWriteDirective) ([]AnnotationWriteDirective, error) {
+	var directives []AnnotationWriteDirective
+	var unknowns Diagnostics
+	var failures GenerationErrors
+
+	for _, item := range a.annotatedDeclarations(declr) {
+		// The package itself is recorded without a declaration name in failures.
+		declaration := item.name
+		if item.kind == packageKind {
+			declaration = ""
+		}
 
-			drs, err := generator(toDir, annotation, structs, declr, pkg)
-			if err != nil {
-				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-					metrics.With("error", err),
-					metrics.With("Level", "Struct"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Struct", structs.Object.Name.Name),
-					metrics.With("Params", annotation.Params),
-					metrics.With("Arguments", annotation.Arguments),
-					metrics.With("Template", annotation.Template))
-				return nil, err
+		// failed records the failure of a annotation of the declaration when keeping going, else
+		// returns the error to stop with.
+		failed := func(err error, annotation AnnotationDeclaration) error {
+			if !run.keepGoing {
+				return err
 			}
 
-			a.metrics.Emit(metrics.Info("Directive Generation: Success"),
-				metrics.With("Level", "Struct"),
-				metrics.With("Directive", len(drs)),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Struct", structs.Object.Name.Name),
-				metrics.With("Params", annotation.Params),
-				metrics.With("Arguments", annotation.Arguments),
-				metrics.With("Template", annotation.Template))
+			failures = append(failures, failuresFor(err, pkg, declaration, annotation, item.location)...)
+			return nil
+		}
 
-			for _, directive := range drs {
-				directives = append(directives, AnnotationWriteDirective{
-					WriteDirective: directive,
-					Annotation:     annotation.Name,
-				})
+		for _, annotation := range item.annotations {
+			if annotation.Defer != deferred {
+				continue
 			}
-		}
-	}
 
-	for _, typ := range declr.Functions {
-		for _, annotation := range typ.Annotations {
+			annotation.Emitted = emitted
+
 			a.metrics.Emit(metrics.Info("Directive Generation"),
-				metrics.With("Level", "Type"),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Function", typ.FuncDeclr.Name.Name),
+				metrics.With("Level", item.level),
+				metrics.With("Annotation", annotation.Name),
+				metrics.With(item.level, item.name),
 				metrics.With("Params", annotation.Params),
 				metrics.With("Arguments", annotation.Arguments),
 				metrics.With("Template", annotation.Template))
 
-			generator, err := a.GetFunctionType(annotation.Name)
+			generator, err := item.generator(annotation.Name)
 			if err != nil {
 				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
 					metrics.With("error", err),
-					metrics.With("Level", "Type"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Function", typ.FuncDeclr.Name.Name),
+					metrics.With("Level", item.level),
+					metrics.With("Annotation", annotation.Name),
+					metrics.With(item.level, item.name),
 					metrics.With("Params", annotation.Params),
 					metrics.With("Arguments", annotation.Arguments),
 					metrics.With("Template", annotation.Template))
+
+				// Unknown annotations are returned together, unless keeping going.
+				if diag, ok := a.unknownAnnotation(item.kind, annotation, item.location); ok {
+					if run.keepGoing {
+						failures = append(failures, GenerationFailure{Diagnostic: diag, Package: pkg.Path, Declaration: declaration})
+					} else {
+						unknowns = append(unknowns, diag)
+					}
+				}
+
 				continue
 			}
 
-			drs, err := generator(toDir, annotation, typ, declr, pkg)
+			annotation, err = a.applySchema(annotation, item.location)
 			if err != nil {
-				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-					metrics.With("error", err),
-					metrics.With("Level", "Type"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Function", typ.FuncDeclr.Name.Name),
-					metrics.With("Params", annotation.Params),
-					metrics.With("Arguments", annotation.Arguments),
-					metrics.With("Template", annotation.Template))
-				return nil, err
-			}
-
-			a.metrics.Emit(metrics.Info("Directive Generation: Success"),
-				metrics.With("Level", "Type"),
-				metrics.With("Directive", len(drs)),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Function", typ.FuncDeclr.Name.Name),
-				metrics.With("Params", annotation.Params),
-				metrics.With("Arguments", annotation.Arguments),
-				metrics.With("Template", annotation.Template))
+				if err := failed(err, annotation); err != nil {
+					return nil, err
+				}
 
-			for _, directive := range drs {
-				directives = append(directives, AnnotationWriteDirective{
-					WriteDirective: directive,
-					Annotation:     annotation.Name,
-				})
+				continue
 			}
-		}
-	}
 
-	for _, typ := range declr.Types {
-		for _, annotation := range typ.Annotations {
-			a.metrics.Emit(metrics.Info("Directive Generation"),
-				metrics.With("Level", "Type"),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Type", typ.Object.Name.Name),
-				metrics.With("Params", annotation.Params),
-				metrics.With("Arguments", annotation.Arguments),
-				metrics.With("Template", annotation.Template))
-
-			generator, err := a.GetType(annotation.Name)
+			annotation, err = associate(annotation, item.associations, declr, pkg, item.location)
 			if err != nil {
-				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
-					metrics.With("error", err),
-					metrics.With("Level", "Type"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Type", typ.Object.Name.Name),
-					metrics.With("Params", annotation.Params),
-					metrics.With("Arguments", annotation.Arguments),
-					metrics.With("Template", annotation.Template))
+				if err := failed(err, annotation); err != nil {
+					return nil, err
+				}
+
 				continue
 			}
 
-			drs, err := generator(toDir, annotation, typ, declr, pkg)
+			drs, err := generator(run.contextFor(annotation, declr, pkg))
 			if err != nil {
 				a.metrics.Emit(metrics.Error(errors.New("Directive Generation")),
 					metrics.With("error", err),
-					metrics.With("Level", "Type"),
-					metrics.With("Annotaton", annotation.Name),
-					metrics.With("Type", typ.Object.Name.Name),
+					metrics.With("Level", item.level),
+					metrics.With("Annotation", annotation.Name),
+					metrics.With(item.level, item.name),
 					metrics.With("Params", annotation.Params),
 					metrics.With("Arguments", annotation.Arguments),
 					metrics.With("Template", annotation.Template))
-				return nil, err
+				if err := failed(DiagnosticFor(err, annotation, item.location), annotation); err != nil {
+					return nil, err
+				}
+
+				continue
 			}
 
 			a.metrics.Emit(metrics.Info("Directive Generation: Success"),
-				metrics.With("Level", "Type"),
+				metrics.With("Level", item.level),
 				metrics.With("Directive", len(drs)),
-				metrics.With("Annotaton", annotation.Name),
-				metrics.With("Type", typ.Object.Name.Name),
+				metrics.With("Annotation", annotation.Name),
+				metrics.With(item.level, item.name),
 				metrics.With("Params", annotation.Params),
 				metrics.With("Arguments", annotation.Arguments),
 				metrics.With("Template", annotation.Template))
@@ -443,19 +692,38 @@ func (a *AnnotationRegistry) ParseDeclr(pkg Package, declr PackageDeclaration, t
 				directives = append(directives, AnnotationWriteDirective{
 					WriteDirective: directive,
 					Annotation:     annotation.Name,
+					Source:         declr.FilePath,
 				})
 			}
 		}
 	}
 
+	if len(unknowns) != 0 {
+		return nil, unknowns
+	}
+
+	if len(failures) != 0 {
+		return directives, failures
+	}
+
 	return directives, nil
 }
 
 // GetPackage returns the annotation generator associated with the giving annotation name.
 func (a *AnnotationRegistry) GetPackage(annotation string) (PackageAnnotationGenerator, error) {
+	generator, err := a.GetPackageContext(annotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.PositionalWith(context.Background(), a.metrics), nil
+}
+
+// GetPackageContext returns the context generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetPackageContext(annotation string) (PackageContextGenerator, error) {
 	annotation = strings.TrimPrefix(annotation, "@")
 
-	var annon PackageAnnotationGenerator
+	var annon PackageContextGenerator
 	var ok bool
 
 	a.ml.RLock()
@@ -493,8 +761,18 @@ func (a *AnnotationRegistry) MustFunctionType(annotation string) FunctionAnnotat
 
 // GetFunctionType returns the annotation generator associated with the giving annotation name.
 func (a *AnnotationRegistry) GetFunctionType(annotation string) (FunctionAnnotationGenerator, error) {
+	generator, err := a.GetFunctionContext(annotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.PositionalWith(context.Background(), a.metrics), nil
+}
+
+// GetFunctionContext returns the context generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetFunctionContext(annotation string) (FunctionContextGenerator, error) {
 	annotation = strings.TrimPrefix(annotation, "@")
-	var annon FunctionAnnotationGenerator
+	var annon FunctionContextGenerator
 	var ok bool
 
 	a.ml.RLock()
@@ -512,8 +790,18 @@ func (a *AnnotationRegistry) GetFunctionType(annotation string) (FunctionAnnotat
 
 // GetInterfaceType returns the annotation generator associated with the giving annotation name.
 func (a *AnnotationRegistry) GetInterfaceType(annotation string) (InterfaceAnnotationGenerator, error) {
+	generator, err := a.GetInterfaceContext(annotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.PositionalWith(context.Background(), a.metrics), nil
+}
+
+// GetInterfaceContext returns the context generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetInterfaceContext(annotation string) (InterfaceContextGenerator, error) {
 	annotation = strings.TrimPrefix(annotation, "@")
-	var annon InterfaceAnnotationGenerator
+	var annon InterfaceContextGenerator
 	var ok bool
 
 	a.ml.RLock()
@@ -541,8 +829,18 @@ func (a *AnnotationRegistry) MustStructType(annotation string) StructAnnotationG
 
 // GetStructType returns the annotation generator associated with the giving annotation name.
 func (a *AnnotationRegistry) GetStructType(annotation string) (StructAnnotationGenerator, error) {
+	generator, err := a.GetStructContext(annotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.PositionalWith(context.Background(), a.metrics), nil
+}
+
+// GetStructContext returns the context generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetStructContext(annotation string) (StructContextGenerator, error) {
 	annotation = strings.TrimPrefix(annotation, "@")
-	var annon StructAnnotationGenerator
+	var annon StructContextGenerator
 	var ok bool
 
 	a.ml.RLock()
@@ -570,9 +868,19 @@ func (a *AnnotationRegistry) MustType(annotation string) TypeAnnotationGenerator
 
 // GetType returns the annotation generator associated with the giving annotation name.
 func (a *AnnotationRegistry) GetType(annotation string) (TypeAnnotationGenerator, error) {
+	generator, err := a.GetTypeContext(annotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.PositionalWith(context.Background(), a.metrics), nil
+}
+
+// GetTypeContext returns the context generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetTypeContext(annotation string) (TypeContextGenerator, error) {
 	annotation = strings.TrimPrefix(annotation, "@")
 
-	var annon TypeAnnotationGenerator
+	var annon TypeContextGenerator
 	var ok bool
 
 	a.ml.RLock()
@@ -588,12 +896,56 @@ func (a *AnnotationRegistry) GetType(annotation string) (TypeAnnotationGenerator
 	return annon, nil
 }
 
+// MustVariableType returns the annotation generator associated with the giving annotation name.
+func (a *AnnotationRegistry) MustVariableType(annotation string) VariableAnnotationGenerator {
+	annon, err := a.GetVariableType(annotation)
+	if err == nil {
+		return annon
+	}
+
+	panic(err)
+}
+
+// GetVariableType returns the annotation generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetVariableType(annotation string) (VariableAnnotationGenerator, error) {
+	generator, err := a.GetVariableContext(annotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.PositionalWith(context.Background(), a.metrics), nil
+}
+
+// GetVariableContext returns the context generator associated with the giving annotation name.
+func (a *AnnotationRegistry) GetVariableContext(annotation string) (VariableContextGenerator, error) {
+	annotation = strings.TrimPrefix(annotation, "@")
+
+	var annon VariableContextGenerator
+	var ok bool
+
+	a.ml.RLock()
+	{
+		annon, ok = a.variableAnnotations[annotation]
+	}
+	a.ml.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("Variable Annotation @%s not found", annotation)
+	}
+
+	return annon, nil
+}
+
 // Register which adds the generator depending on it's type into the appropriate
 // registry. It only supports  the following generators:
 // 1. TypeAnnotationGenerator (see Package ast#TypeAnnotationGenerator)
 // 2. StructAnnotationGenerator (see Package ast#StructAnnotationGenerator)
 // 3. InterfaceAnnotationGenerator (see Package ast#InterfaceAnnotationGenerator)
 // 4. PackageAnnotationGenerator (see Package ast#PackageAnnotationGenerator)
+// 5. FunctionAnnotationGenerator (see Package ast#FunctionAnnotationGenerator)
+// 6. VariableAnnotationGenerator (see Package ast#VariableAnnotationGenerator)
+// 7. The context generators of each, which receive a GeneratorContext (see Package ast#StructContextGenerator)
+// AnnotationGenerators are adapted into context generators (see StructAnnotationGenerator.Contextual).
 // Any other type will cause the return of an error.
 func (a *AnnotationRegistry) Register(name string, generator interface{}) error {
 	switch gen := generator.(type) {
@@ -615,12 +967,60 @@ func (a *AnnotationRegistry) Register(name string, generator interface{}) error
 	case func(string, AnnotationDeclaration, StructDeclaration, PackageDeclaration, Package) ([]gen.WriteDirective, error):
 		a.RegisterStructType(name, gen)
 		return nil
+	case FunctionAnnotationGenerator:
+		a.RegisterFunctionType(name, gen)
+		return nil
+	case func(string, AnnotationDeclaration, FuncDeclaration, PackageDeclaration, Package) ([]gen.WriteDirective, error):
+		a.RegisterFunctionType(name, gen)
+		return nil
 	case InterfaceAnnotationGenerator:
 		a.RegisterInterfaceType(name, gen)
 		return nil
 	case func(string, AnnotationDeclaration, InterfaceDeclaration, PackageDeclaration, Package) ([]gen.WriteDirective, error):
 		a.RegisterInterfaceType(name, gen)
 		return nil
+	case VariableAnnotationGenerator:
+		a.RegisterVariableType(name, gen)
+		return nil
+	case func(string, AnnotationDeclaration, VariableDeclaration, PackageDeclaration, Package) ([]gen.WriteDirective, error):
+		a.RegisterVariableType(name, gen)
+		return nil
+	case PackageContextGenerator:
+		a.RegisterPackageContext(name, gen)
+		return nil
+	case func(GeneratorContext) ([]gen.WriteDirective, error):
+		a.RegisterPackageContext(name, gen)
+		return nil
+	case TypeContextGenerator:
+		a.RegisterTypeContext(name, gen)
+		return nil
+	case func(GeneratorContext, TypeDeclaration) ([]gen.WriteDirective, error):
+		a.RegisterTypeContext(name, gen)
+		return nil
+	case StructContextGenerator:
+		a.RegisterStructContext(name, gen)
+		return nil
+	case func(GeneratorContext, StructDeclaration) ([]gen.WriteDirective, error):
+		a.RegisterStructContext(name, gen)
+		return nil
+	case FunctionContextGenerator:
+		a.RegisterFunctionContext(name, gen)
+		return nil
+	case func(GeneratorContext, FuncDeclaration) ([]gen.WriteDirective, error):
+		a.RegisterFunctionContext(name, gen)
+		return nil
+	case InterfaceContextGenerator:
+		a.RegisterInterfaceContext(name, gen)
+		return nil
+	case func(GeneratorContext, InterfaceDeclaration) ([]gen.WriteDirective, error):
+		a.RegisterInterfaceContext(name, gen)
+		return nil
+	case VariableContextGenerator:
+		a.RegisterVariableContext(name, gen)
+		return nil
+	case func(GeneratorContext, VariableDeclaration) ([]gen.WriteDirective, error):
+		a.RegisterVariableContext(name, gen)
+		return nil
 	default:
 		return fmt.Errorf("Generator type for %q not supported: %#v", name, generator)
 	}
@@ -628,6 +1028,11 @@ func (a *AnnotationRegistry) Register(name string, generator interface{}) error
 
 // RegisterInterfaceType adds a interface type level annotation generator into the registry.
 func (a *AnnotationRegistry) RegisterInterfaceType(annotation string, generator InterfaceAnnotationGenerator) {
+	a.RegisterInterfaceContext(annotation, generator.Contextual())
+}
+
+// RegisterInterfaceContext adds a interface type level context generator into the registry.
+func (a *AnnotationRegistry) RegisterInterfaceContext(annotation string, generator InterfaceContextGenerator) {
 	annotation = strings.TrimPrefix(annotation, "@")
 	a.ml.Lock()
 	{
@@ -636,8 +1041,28 @@ func (a *AnnotationRegistry) RegisterInterfaceType(annotation string, generator
 	a.ml.Unlock()
 }
 
+// RegisterFunctionType adds a function/method level annotation generator into the registry.
+func (a *AnnotationRegistry) RegisterFunctionType(annotation string, generator FunctionAnnotationGenerator) {
+	a.RegisterFunctionContext(annotation, generator.Contextual())
+}
+
+// RegisterFunctionContext adds a function/method level context generator into the registry.
+func (a *AnnotationRegistry) RegisterFunctionContext(annotation string, generator FunctionContextGenerator) {
+	annotation = strings.TrimPrefix(annotation, "@")
+	a.ml.Lock()
+	{
+		a.functionAnnotations[annotation] = generator
+	}
+	a.ml.Unlock()
+}
+
 // RegisterStructType adds a struct type level annotation generator into the registry.
 func (a *AnnotationRegistry) RegisterStructType(annotation string, generator StructAnnotationGenerator) {
+	a.RegisterStructContext(annotation, generator.Contextual())
+}
+
+// RegisterStructContext adds a struct type level context generator into the registry.
+func (a *AnnotationRegistry) RegisterStructContext(annotation string, generator StructContextGenerator) {
 	annotation = strings.TrimPrefix(annotation, "@")
 	a.ml.Lock()
 	{
@@ -648,6 +1073,11 @@ func (a *AnnotationRegistry) RegisterStructType(annotation string, generator Str
 
 // RegisterType adds a type(non-struct, non-interface) level annotation generator into the registry.
 func (a *AnnotationRegistry) RegisterType(annotation string, generator TypeAnnotationGenerator) {
+	a.RegisterTypeContext(annotation, generator.Contextual())
+}
+
+// RegisterTypeContext adds a type(non-struct, non-interface) level context generator into the registry.
+func (a *AnnotationRegistry) RegisterTypeContext(annotation string, generator TypeContextGenerator) {
 	annotation = strings.TrimPrefix(annotation, "@")
 	a.ml.Lock()
 	{
@@ -656,8 +1086,28 @@ func (a *AnnotationRegistry) RegisterType(annotation string, generator TypeAnnot
 	a.ml.Unlock()
 }
 
+// RegisterVariableType adds a package level variable/constant annotation generator into the registry.
+func (a *AnnotationRegistry) RegisterVariableType(annotation string, generator VariableAnnotationGenerator) {
+	a.RegisterVariableContext(annotation, generator.Contextual())
+}
+
+// RegisterVariableContext adds a package level variable/constant context generator into the registry.
+func (a *AnnotationRegistry) RegisterVariableContext(annotation string, generator VariableContextGenerator) {
+	annotation = strings.TrimPrefix(annotation, "@")
+	a.ml.Lock()
+	{
+		a.variableAnnotations[annotation] = generator
+	}
+	a.ml.Unlock()
+}
+
 // RegisterPackage adds a package level annotation generator into the registry.
 func (a *AnnotationRegistry) RegisterPackage(annotation string, generator PackageAnnotationGenerator) {
+	a.RegisterPackageContext(annotation, generator.Contextual())
+}
+
+// RegisterPackageContext adds a package level context generator into the registry.
+func (a *AnnotationRegistry) RegisterPackageContext(annotation string, generator PackageContextGenerator) {
 	annotation = strings.TrimPrefix(annotation, "@")
 	a.ml.Lock()
 	{
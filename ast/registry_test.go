@@ -0,0 +1,767 @@
+package ast_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+var variableSource = `package mock
+
+// Routes defines the handlers for each route.
+// @route(api)
+var Routes = map[string]string{}
+
+// Defaults for the service.
+const (
+	// @config
+	Port = 8080
+
+	Host = "localhost"
+)
+`
+
+// TestVariableAnnotationGenerator validates the running of generators for annotated variables and constants.
+func TestVariableAnnotationGenerator(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": variableSource})
+
+	var visited []string
+	generator := func(toDir string, an ast.AnnotationDeclaration, variable ast.VariableDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		visited = append(visited, an.Name+":"+variable.Name)
+		return []gen.WriteDirective{{FileName: variable.Name + ".go"}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	if err := registry.Register("route", generator); err != nil {
+		tests.Failed("Should have registered variable generator: %+q", err)
+	}
+	tests.Passed("Should have registered variable generator")
+
+	if err := registry.Register("config", ast.VariableAnnotationGenerator(generator)); err != nil {
+		tests.Failed("Should have registered variable generator: %+q", err)
+	}
+	tests.Passed("Should have registered variable generator")
+
+	directives, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+	if err != nil {
+		tests.Failed("Should have successfully generated directives: %+q", err)
+	}
+	tests.Passed("Should have successfully generated directives")
+
+	if len(directives) != 2 || len(visited) != 2 || visited[0] != "@route:Routes" || visited[1] != "@config:Port" {
+		tests.Info("Visited: %+q", visited)
+		tests.Failed("Should have run generators for annotated variable and constant")
+	}
+	tests.Passed("Should have run generators for annotated variable and constant")
+
+	for _, variable := range pkgs[0].Packages[0].Variables {
+		if variable.Name == "Port" && !variable.IsConstant() {
+			tests.Failed("Should have marked Port as constant")
+		}
+
+		if variable.Name == "Host" && len(variable.Annotations) != 0 {
+			tests.Failed("Should have not annotated Host")
+		}
+	}
+	tests.Passed("Should have read annotations of grouped constants")
+
+	copied := ast.NewAnnotationRegistry()
+	copied.Copy(registry, ast.OursOverTheirs)
+
+	if _, err := copied.GetVariableType("config"); err != nil {
+		tests.Failed("Should have copied variable generator: %+q", err)
+	}
+	tests.Passed("Should have copied variable generator")
+
+	if len(registry.Clone().Variables) != 2 {
+		tests.Failed("Should have cloned variable generators")
+	}
+	tests.Passed("Should have cloned variable generators")
+}
+
+var strictSource = `package mock
+
+// User defines a user of the service.
+// @mogno(collection => users)
+// @validate
+type User struct{}
+
+// Store defines the storage of users.
+// @mongo
+// @mock
+type Store interface{}
+`
+
+// TestStrictAnnotationRegistry validates the reporting of unknown and misplaced annotations in strict mode.
+func TestStrictAnnotationRegistry(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": strictSource})
+
+	generator := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		return nil, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("mongo", generator)
+	registry.Allow("@mock")
+
+	if _, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock"); err != nil {
+		tests.Failed("Should have ignored unknown annotations without strict mode: %+q", err)
+	}
+	tests.Passed("Should have ignored unknown annotations without strict mode")
+
+	registry.SetStrict(true)
+
+	_, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+
+	diagnostics, ok := err.(ast.Diagnostics)
+	if !ok || len(diagnostics) != 3 {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have reported unknown annotations in strict mode")
+	}
+	tests.Passed("Should have reported unknown annotations in strict mode")
+
+	expected := []string{
+		"@mongo: no interface generator registered, annotation is registered for struct declarations",
+		"@mogno: unknown struct annotation, did you mean @mongo (struct)?",
+		"@validate: unknown struct annotation",
+	}
+
+	for index, diag := range diagnostics {
+		if diag.Annotation+": "+diag.Message != expected[index] {
+			tests.Info("Diagnostic: %s", diag)
+			tests.Failed("Should have reported %q", expected[index])
+		}
+		tests.Passed("Should have reported %q", expected[index])
+	}
+
+	if diagnostics[1].Position.Line != 4 {
+		tests.Info("Diagnostic: %s", diagnostics[1])
+		tests.Failed("Should have positioned diagnostic at @mogno annotation")
+	}
+	tests.Passed("Should have positioned diagnostic at @mogno annotation")
+}
+
+var schemaSource = `package mock
+
+// User defines a user of the service.
+// @mongo(colection => users, limit => many, mode => sync)
+type User struct{}
+
+// Group defines a group of users.
+// @mongo(collection => groups)
+type Group struct{}
+`
+
+// TestAnnotationSchema validates the validation and default filling of annotation params with a schema.
+func TestAnnotationSchema(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": schemaSource})
+
+	var received []ast.AnnotationDeclaration
+	generator := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		received = append(received, an)
+		return nil, nil
+	}
+
+	schema := ast.AnnotationSchema{
+		Description: "Generates mongo CRUD functions for a struct.",
+		Params: []ast.ParamSchema{
+			{Name: "collection", Type: ast.ParamString, Required: true},
+			{Name: "limit", Type: ast.ParamInt, Default: "20"},
+			{Name: "mode", Type: ast.ParamString, Enum: []string{"async", "batch"}, Default: "async"},
+		},
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	if err := registry.RegisterWithSchema("mongo", generator, schema); err != nil {
+		tests.Failed("Should have registered generator with schema: %+q", err)
+	}
+	tests.Passed("Should have registered generator with schema")
+
+	_, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+
+	diagnostics, ok := err.(ast.Diagnostics)
+	if !ok || len(diagnostics) != 4 || len(received) != 0 {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have reported schema violations")
+	}
+	tests.Passed("Should have reported schema violations")
+
+	expected := []string{
+		`unknown param "colection", did you mean "collection"?`,
+		`missing required param "collection"`,
+		`param "limit" must be a int, got many`,
+		`param "mode" must be one of async, batch, got sync`,
+	}
+
+	for index, diag := range diagnostics {
+		if diag.Message != expected[index] || diag.Annotation != "@mongo" || diag.Position.Line != 4 {
+			tests.Info("Diagnostic: %s", diag)
+			tests.Failed("Should have reported %q", expected[index])
+		}
+		tests.Passed("Should have reported %q", expected[index])
+	}
+
+	groupDeclr := pkgs[0].Packages[0]
+	groupDeclr.Structs = groupDeclr.Structs[1:]
+
+	if _, err := registry.ParseDeclr(pkgs[0], groupDeclr, "mock"); err != nil {
+		tests.Failed("Should have validated annotation against schema: %+q", err)
+	}
+	tests.Passed("Should have validated annotation against schema")
+
+	if len(received) != 1 || received[0].Param("limit") != "20" || received[0].Param("mode") != "async" {
+		tests.Info("Received: %+v", received)
+		tests.Failed("Should have filled defaults of missing params")
+	}
+	tests.Passed("Should have filled defaults of missing params")
+
+	if limit, err := received[0].IntParam("limit"); err != nil || limit != 20 {
+		tests.Failed("Should have filled typed value of default param")
+	}
+	tests.Passed("Should have filled typed value of default param")
+}
+
+var associationSource = `package mock
+
+// User defines a user of the service.
+type User struct{}
+
+// UserStore defines the store of users.
+// @mongo
+// @associates(@mongo, Save, User)
+type UserStore interface{}
+
+// GroupStore defines the store of groups.
+// @mongo
+// @associates(@mongo, Save, Group)
+type GroupStore interface{}
+`
+
+// TestAnnotationAssociations validates the resolution of associations before invoking generators.
+func TestAnnotationAssociations(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": associationSource})
+
+	var received []ast.AnnotationDeclaration
+	generator := func(toDir string, an ast.AnnotationDeclaration, inter ast.InterfaceDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		received = append(received, an)
+		return nil, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("mongo", generator)
+
+	pkgs.ResolveAssociations()
+
+	userDeclr := pkgs[0].Packages[0]
+	userDeclr.Interfaces = userDeclr.Interfaces[:1]
+
+	if association := userDeclr.Interfaces[0].Associations["@mongo"]; !association.Resolved() {
+		tests.Info("Association: %+v", association)
+		tests.Failed("Should have resolved association of UserStore interface")
+	}
+	tests.Passed("Should have resolved association of UserStore interface")
+
+	if _, err := registry.ParseDeclr(pkgs[0], userDeclr, "mock"); err != nil {
+		tests.Failed("Should have generated directives for UserStore interface: %+q", err)
+	}
+	tests.Passed("Should have generated directives for UserStore interface")
+
+	if len(received) != 1 || received[0].Association == nil {
+		tests.Info("Received: %+v", received)
+		tests.Failed("Should have passed association to generator")
+	}
+	tests.Passed("Should have passed association to generator")
+
+	association := received[0].Association
+	if association.Action != "Save" || association.Kind != "struct" || association.Struct == nil || association.Struct.Name != "User" {
+		tests.Info("Association: %+v", association)
+		tests.Failed("Should have resolved User struct of association")
+	}
+	tests.Passed("Should have resolved User struct of association")
+
+	_, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+
+	diag, ok := err.(ast.Diagnostic)
+	if !ok || diag.Annotation != "@associates" || diag.Position.Line != 13 {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have reported missing associated type at @associates annotation")
+	}
+	tests.Passed("Should have reported missing associated type at @associates annotation")
+
+	if diag.Message != `associated type "Group" for @mongo not found` {
+		tests.Info("Diagnostic: %s", diag)
+		tests.Failed("Should have reported missing Group type")
+	}
+	tests.Passed("Should have reported missing Group type")
+}
+
+var templateSource = `package mock
+
+// User defines a user of the service.
+// @template(file => user_fields.go, dir => fields, prefix => User, {
+//   package fields
+//
+//   // {{annotation.Param "prefix"}}Fields contains the fields of {{.Name}}.
+//   var {{annotation.Param "prefix"}}Fields = []string{ {{range getFields . .Declr}}"{{.FieldName}}", {{end}}}
+// })
+type User struct {
+	Name  string
+	Email string
+}
+
+// Group defines a group of users.
+// @template(file => group.go, {
+//   {{.Missing}}
+// })
+type Group struct{}
+`
+
+// TestTemplateAnnotation validates the rendering of the template of the built-in @template annotation.
+func TestTemplateAnnotation(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": templateSource})
+
+	registry := ast.NewAnnotationRegistry()
+	if _, err := registry.GetStructType("template"); err == nil {
+		tests.Failed("Should have not registered @template annotation by default")
+	}
+	tests.Passed("Should have not registered @template annotation by default")
+
+	registry.RegisterTemplate()
+
+	userDeclr := pkgs[0].Packages[0]
+	userDeclr.Structs = userDeclr.Structs[:1]
+
+	directives, err := registry.ParseDeclr(pkgs[0], userDeclr, "mock")
+	if err != nil {
+		tests.Failed("Should have rendered template of User struct: %+q", err)
+	}
+	tests.Passed("Should have rendered template of User struct")
+
+	if len(directives) != 1 || directives[0].Annotation != "@template" || directives[0].FileName != "user_fields.go" || directives[0].Dir != "fields" {
+		tests.Info("Directives: %+v", directives)
+		tests.Failed("Should have created directive for user_fields.go")
+	}
+	tests.Passed("Should have created directive for user_fields.go")
+
+	var bu bytes.Buffer
+	if _, err := directives[0].Writer.WriteTo(&bu); err != nil {
+		tests.Failed("Should have written rendered template: %+q", err)
+	}
+	tests.Passed("Should have written rendered template")
+
+	expected := "package fields\n\n  // UserFields contains the fields of User.\n  var UserFields = []string{ \"Name\", \"Email\", }"
+	if bu.String() != expected {
+		tests.Info("Rendered: %q", bu.String())
+		tests.Failed("Should have rendered template with User struct as data")
+	}
+	tests.Passed("Should have rendered template with User struct as data")
+
+	_, err = registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+
+	diag, ok := err.(ast.Diagnostic)
+	if !ok || diag.Annotation != "@template" || diag.Position.Line != 16 {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have reported template error of Group struct at it's annotation")
+	}
+	tests.Passed("Should have reported template error of Group struct at it's annotation")
+}
+
+var deferredSource = `package mock
+
+// Routes defines the routes of the service.
+// @routes(defer => true)
+type Routes struct{}
+
+// User defines a user of the service.
+// @handler
+type User struct{}
+
+// Group defines a group of users.
+// @handler
+type Group struct{}
+`
+
+// TestDeferredAnnotations validates the generation of deferred annotations after all others.
+func TestDeferredAnnotations(t *testing.T) {
+	_, pkgs := loadPackage(t, map[string]string{"mock.go": deferredSource})
+
+	handler := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		return []gen.WriteDirective{{FileName: str.Name + "_handler.go"}}, nil
+	}
+
+	var emitted []ast.AnnotationWriteDirective
+	routes := func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		emitted = an.Emitted
+		return []gen.WriteDirective{{FileName: "routes.go"}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("handler", handler)
+	registry.Register("routes", routes)
+
+	if routesStruct, ok := pkgs[0].StructFor("Routes"); !ok || !routesStruct.Annotations[0].Defer {
+		tests.Failed("Should have marked @routes annotation as deferred")
+	}
+	tests.Passed("Should have marked @routes annotation as deferred")
+
+	directives, err := registry.ParsePackages(pkgs, "mock")
+	if err != nil {
+		tests.Failed("Should have generated directives for packages: %+q", err)
+	}
+	tests.Passed("Should have generated directives for packages")
+
+	var files []string
+	for _, directive := range directives {
+		files = append(files, directive.FileName)
+	}
+
+	if len(files) != 3 || files[0] != "User_handler.go" || files[1] != "Group_handler.go" || files[2] != "routes.go" {
+		tests.Info("Files: %+v", files)
+		tests.Failed("Should have generated deferred annotation after all others")
+	}
+	tests.Passed("Should have generated deferred annotation after all others")
+
+	if len(emitted) != 2 || emitted[0].Annotation != "@handler" || emitted[1].FileName != "Group_handler.go" || filepath.Base(emitted[0].Source) != "mock.go" {
+		tests.Info("Emitted: %+v", emitted)
+		tests.Failed("Should have passed directives of earlier annotations to deferred annotation")
+	}
+	tests.Passed("Should have passed directives of earlier annotations to deferred annotation")
+
+	emitted = nil
+	if _, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock"); err != nil {
+		tests.Failed("Should have generated directives for declaration: %+q", err)
+	}
+	tests.Passed("Should have generated directives for declaration")
+
+	if len(emitted) != 2 {
+		tests.Info("Emitted: %+v", emitted)
+		tests.Failed("Should have passed directives of declaration to deferred annotation")
+	}
+	tests.Passed("Should have passed directives of declaration to deferred annotation")
+}
+
+// runMetrics defines a comparable metrics.Metrics.
+type runMetrics struct {
+	metrics.Metrics
+}
+
+// TestGeneratorContext validates the invocation of context generators alongside AnnotationGenerators.
+func TestGeneratorContext(t *testing.T) {
+	dir, pkgs := loadPackage(t, map[string]string{
+		"go.mod":  "module example.com/mock\n",
+		"mock.go": deferredSource,
+	})
+
+	var contexts []ast.GeneratorContext
+	handler := func(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+		contexts = append(contexts, ctx)
+		suffix, _ := ctx.Option("suffix")
+		return []gen.WriteDirective{{FileName: str.Name + suffix}}, nil
+	}
+
+	var emitted []ast.AnnotationWriteDirective
+	routes := func(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+		emitted = ctx.Emitted("handler")
+		return []gen.WriteDirective{{FileName: "routes.go"}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	if err := registry.Register("handler", handler); err != nil {
+		tests.Failed("Should have registered context generator: %+q", err)
+	}
+	tests.Passed("Should have registered context generator")
+
+	registry.Register("routes", routes)
+
+	config := ast.ParseConfig{
+		Context: context.Background(),
+		Dir:     dir,
+		Options: map[string]string{"suffix": "_handler.go"},
+	}
+
+	directives, err := registry.ParsePackagesWith(config, pkgs, "example.com/mock")
+	if err != nil {
+		tests.Failed("Should have generated directives for packages: %+q", err)
+	}
+	tests.Passed("Should have generated directives for packages")
+
+	if len(directives) != 3 || directives[0].FileName != "User_handler.go" {
+		tests.Info("Directives: %+v", directives)
+		tests.Failed("Should have provided options to context generator")
+	}
+	tests.Passed("Should have provided options to context generator")
+
+	if len(contexts) != 2 || contexts[0].Module != "example.com/mock" || contexts[0].ToPath != "example.com/mock" || len(contexts[0].Packages) != 1 || contexts[0].Metrics == nil {
+		tests.Info("Contexts: %+v", contexts)
+		tests.Failed("Should have provided run details to context generator")
+	}
+	tests.Passed("Should have provided run details to context generator")
+
+	if len(emitted) != 2 || emitted[1].FileName != "Group_handler.go" {
+		tests.Info("Emitted: %+v", emitted)
+		tests.Failed("Should have provided emitted directives to deferred context generator")
+	}
+	tests.Passed("Should have provided emitted directives to deferred context generator")
+
+	generator, err := registry.GetStructType("handler")
+	if err != nil {
+		tests.Failed("Should have returned context generator as AnnotationGenerator: %+q", err)
+	}
+
+	str, _ := pkgs[0].StructFor("User")
+	if drs, err := generator("mock", str.Annotations[0], str, pkgs[0].Packages[0], pkgs[0]); err != nil || len(drs) != 1 || drs[0].FileName != "User" {
+		tests.Info("Directives: %+v: %+v", drs, err)
+		tests.Failed("Should have returned context generator as AnnotationGenerator")
+	}
+	tests.Passed("Should have returned context generator as AnnotationGenerator")
+
+	cloned := registry.Clone()
+	if cloned.Structs["handler"] == nil || cloned.StructContexts["handler"] == nil {
+		tests.Info("Annotations: %+v", cloned)
+		tests.Failed("Should have cloned both AnnotationGenerator and context generator")
+	}
+	tests.Passed("Should have cloned both AnnotationGenerator and context generator")
+
+	running, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	log := &runMetrics{Metrics: metrics.New()}
+	if _, err := ast.StructContextGenerator(handler).PositionalWith(running, log)("mock", str.Annotations[0], str, pkgs[0].Packages[0], pkgs[0]); err != nil {
+		tests.Failed("Should have run context generator as AnnotationGenerator: %+q", err)
+	}
+
+	if last := contexts[len(contexts)-1]; last.Context != running || last.Metrics != log {
+		tests.Info("Context: %+v", last)
+		tests.Failed("Should have provided context and metrics to context generator run as AnnotationGenerator")
+	}
+	tests.Passed("Should have provided context and metrics to context generator run as AnnotationGenerator")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := registry.ParsePackagesWith(ast.ParseConfig{Context: cancelled}, pkgs, "example.com/mock"); err != context.Canceled {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have stopped generation with cancelled context")
+	}
+	tests.Passed("Should have stopped generation with cancelled context")
+}
+
+// TestParallelGeneration validates the concurrent generation of declarations in the order of the declarations.
+func TestParallelGeneration(t *testing.T) {
+	files := make(map[string]string)
+	for index := 0; index < 8; index++ {
+		files[fmt.Sprintf("model%d.go", index)] = fmt.Sprintf("package mock\n\n// Model%d defines a model.\n// @slow\ntype Model%d struct{}\n", index, index)
+	}
+
+	dir, pkgs := loadPackage(t, files)
+
+	var running, concurrent int32
+	slow := func(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&concurrent)
+			if current <= max || atomic.CompareAndSwapInt32(&concurrent, max, current) {
+				break
+			}
+		}
+
+		// Later declarations complete first.
+		time.Sleep(time.Duration(8-int(str.Name[len(str.Name)-1]-'0')) * 2 * time.Millisecond)
+		return []gen.WriteDirective{{FileName: str.Name + ".go"}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.Register("slow", slow)
+
+	serial, err := registry.ParsePackagesWith(ast.ParseConfig{}, pkgs, "mock")
+	if err != nil {
+		tests.Failed("Should have generated directives serially: %+q", err)
+	}
+	tests.Passed("Should have generated directives serially")
+
+	if concurrent != 1 {
+		tests.Info("Concurrent: %d", concurrent)
+		tests.Failed("Should have run generators one at a time by default")
+	}
+	tests.Passed("Should have run generators one at a time by default")
+
+	if len(serial) != 8 {
+		tests.Info("Serial: %+v", serial)
+		tests.Failed("Should have generated directives for all declarations")
+	}
+
+	for index, directive := range serial {
+		if directive.FileName != fmt.Sprintf("Model%d.go", index) {
+			tests.Info("Serial: %+v", serial)
+			tests.Failed("Should have returned directives in the order of the files")
+		}
+	}
+	tests.Passed("Should have returned directives in the order of the files")
+
+	parallel, err := registry.ParsePackagesWith(ast.ParseConfig{Workers: 4}, pkgs, "mock")
+	if err != nil {
+		tests.Failed("Should have generated directives concurrently: %+q", err)
+	}
+	tests.Passed("Should have generated directives concurrently")
+
+	if concurrent < 2 || concurrent > 4 {
+		tests.Info("Concurrent: %d", concurrent)
+		tests.Failed("Should have run generators across bounded workers")
+	}
+	tests.Passed("Should have run generators across bounded workers")
+
+	reloaded, err := ast.FilteredPackageWithBuildCtx(metrics.New(), dir, build.Default)
+	if err != nil {
+		tests.Failed("Should have reloaded package: %+q", err)
+	}
+
+	again, err := registry.ParsePackagesWith(ast.ParseConfig{Workers: 4}, reloaded, "mock")
+	if err != nil {
+		tests.Failed("Should have generated directives of reloaded package: %+q", err)
+	}
+
+	if len(again) != len(parallel) {
+		tests.Info("Parallel: %+v, Reloaded: %+v", parallel, again)
+		tests.Failed("Should have generated directives for all declarations of reloaded package")
+	}
+
+	for index := range parallel {
+		if parallel[index].FileName != again[index].FileName || parallel[index].Source != again[index].Source {
+			tests.Info("Parallel: %+v, Reloaded: %+v", parallel, again)
+			tests.Failed("Should have returned directives in the same order across loads")
+		}
+	}
+	tests.Passed("Should have returned directives in the same order across loads")
+
+	failing := func(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+		if str.Name == "Model3" {
+			return nil, errors.New("model not supported")
+		}
+
+		return slow(ctx, str)
+	}
+
+	registry.Register("slow", failing)
+
+	_, err = registry.ParsePackagesWith(ast.ParseConfig{Workers: 4}, pkgs, "mock")
+	if diag, ok := err.(ast.Diagnostic); !ok || diag.Message != "model not supported" {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have returned error of failing generator")
+	}
+	tests.Passed("Should have returned error of failing generator")
+}
+
+var keepGoingSource = `package mock
+
+// User defines a user of the service.
+// @model
+type User struct{}
+
+// Group defines a group of users.
+// @model
+type Group struct{}
+
+// Team defines a team of users.
+// @model(limit => many)
+type Team struct{}
+
+// Models lists the models of the service.
+// @models(defer => true)
+type Models struct{}
+`
+
+// TestKeepGoing validates the collection of all generator failures with the directives of the others.
+func TestKeepGoing(t *testing.T) {
+	dir, pkgs := loadPackage(t, map[string]string{
+		"go.mod":  "module example.com/mock\n",
+		"mock.go": keepGoingSource,
+	})
+
+	model := func(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+		if str.Name == "Group" {
+			return nil, errors.New("groups are not supported")
+		}
+
+		return []gen.WriteDirective{{FileName: strings.ToLower(str.Name) + "_model.go", Writer: gen.Text("package mock\n")}}, nil
+	}
+
+	var emitted []ast.AnnotationWriteDirective
+	models := func(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+		emitted = ctx.Emitted()
+		return []gen.WriteDirective{{FileName: "models.go", Writer: gen.Text("package mock\n")}}, nil
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.RegisterWithSchema("model", model, ast.AnnotationSchema{
+		Params: []ast.ParamSchema{{Name: "limit", Type: ast.ParamInt}},
+	})
+	registry.Register("models", models)
+
+	if _, err := registry.ParsePackagesWith(ast.ParseConfig{}, pkgs, "example.com/mock"); err == nil || !strings.Contains(err.Error(), "groups are not supported") {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have stopped at first failure without keep going")
+	}
+	tests.Passed("Should have stopped at first failure without keep going")
+
+	directives, err := registry.ParsePackagesWith(ast.ParseConfig{KeepGoing: true, Workers: 2}, pkgs, "example.com/mock")
+	failures, ok := err.(ast.GenerationErrors)
+	if !ok || len(failures) != 2 {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have returned all failures as GenerationErrors")
+	}
+	tests.Passed("Should have returned all failures as GenerationErrors")
+
+	if failures[0].Declaration != "Group" || failures[0].Package != "example.com/mock" || failures[0].Position.Line != 8 || failures[0].Message != "groups are not supported" {
+		tests.Info("Failure: %#v", failures[0])
+		tests.Failed("Should have recorded package, declaration and position of generator failure")
+	}
+	tests.Passed("Should have recorded package, declaration and position of generator failure")
+
+	if failures[1].Declaration != "Team" || failures[1].Annotation != "@model" || !strings.Contains(failures[1].Message, `param "limit" must be a int`) {
+		tests.Info("Failure: %#v", failures[1])
+		tests.Failed("Should have recorded schema violation as failure")
+	}
+	tests.Passed("Should have recorded schema violation as failure")
+
+	if !strings.HasPrefix(err.Error(), "Generation failed for 2 annotation(s) in 1 package(s): example.com/mock (2)") || !strings.Contains(err.Error(), "(in Group of example.com/mock)") {
+		tests.Info("Error: %s", err)
+		tests.Failed("Should have summarised failures")
+	}
+	tests.Passed("Should have summarised failures")
+
+	if len(directives) != 2 || directives[0].FileName != "user_model.go" || directives[1].FileName != "models.go" || len(emitted) != 1 {
+		tests.Info("Directives: %+v", directives)
+		tests.Failed("Should have returned directives of successful generators")
+	}
+	tests.Passed("Should have returned directives of successful generators")
+
+	if directives, err := registry.ParsePackagesWith(ast.ParseConfig{KeepGoing: true, AllOrNothing: true}, pkgs, "example.com/mock"); len(directives) != 0 || err == nil {
+		tests.Info("Directives: %+v: %+v", directives, err)
+		tests.Failed("Should have returned no directive with failures in all-or-nothing mode")
+	}
+	tests.Passed("Should have returned no directive with failures in all-or-nothing mode")
+
+	if err := ast.ParseWith(dir, metrics.New(), registry, true, ast.ParseConfig{KeepGoing: true}, pkgs...); err == nil {
+		tests.Failed("Should have returned failures after writing directives")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "user_model.go")); err != nil {
+		tests.Failed("Should have written directives of successful generators: %+q", err)
+	}
+	tests.Passed("Should have written directives of successful generators")
+}
@@ -0,0 +1,274 @@
+package ast
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// ParamType defines the type expected of the value of a annotation param.
+type ParamType string
+
+// Contains the different types of annotation params.
+const (
+	ParamAny    ParamType = ""
+	ParamString ParamType = "string"
+	ParamInt    ParamType = "int"
+	ParamFloat  ParamType = "float"
+	ParamBool   ParamType = "bool"
+	ParamList   ParamType = "list"
+	ParamMap    ParamType = "map"
+)
+
+// implicitParams contains the params read by the annotation parser itself, which are
+// allowed on all annotations.
+var implicitParams = map[string]bool{
+	"defer":  true,
+	"Defer":  true,
+	"asJSON": true,
+}
+
+// ParamSchema defines the schema of a `key => value` param of a annotation.
+type ParamSchema struct {
+	Name        string
+	Type        ParamType
+	Required    bool
+	Description string
+
+	// Default is the value used for the param when missing, written as it would be
+	// within the annotation (e.g `20`, `"users"`, `[a, b]`).
+	Default string
+
+	// Enum contains the allowed values of the param, if any.
+	Enum []string
+}
+
+// AnnotationSchema defines the params accepted by a annotation, which the AnnotationRegistry
+// validates and default-fills a AnnotationDeclaration with before invoking it's generator.
+type AnnotationSchema struct {
+	Description string
+	Params      []ParamSchema
+
+	// AllowUnknown allows params not declared within Params.
+	AllowUnknown bool
+}
+
+// Param returns the schema of the param with giving name.
+func (schema AnnotationSchema) Param(name string) (ParamSchema, bool) {
+	for _, param := range schema.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+
+	return ParamSchema{}, false
+}
+
+// Validate returns a copy of the annotation with the defaults of missing params filled, and the
+// diagnostics of params which are unknown, missing, of the wrong type or not within their enum.
+// Diagnostics are positioned at the annotation.
+func (schema AnnotationSchema) Validate(annotation AnnotationDeclaration) (AnnotationDeclaration, Diagnostics) {
+	var diagnostics Diagnostics
+
+	diag := func(message string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity:   SeverityError,
+			Position:   annotation.Position,
+			Annotation: annotation.Name,
+			Message:    fmt.Sprintf(message, args...),
+		})
+	}
+
+	params := make(map[string]string, len(annotation.Params)+len(schema.Params))
+	for key, value := range annotation.Params {
+		params[key] = value
+	}
+
+	paramValues := make(map[string]AnnotationValue, len(annotation.ParamValues)+len(schema.Params))
+	for key, value := range annotation.ParamValues {
+		paramValues[key] = value
+	}
+
+	annotation.Params = params
+	annotation.ParamValues = paramValues
+
+	if !schema.AllowUnknown {
+		keys := make([]string, 0, len(params))
+		for key := range params {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if implicitParams[key] {
+				continue
+			}
+
+			if schema.hasParam(key) {
+				continue
+			}
+
+			if suggestion := schema.nearestParam(key); suggestion != "" {
+				diag("unknown param %q, did you mean %q?", key, suggestion)
+				continue
+			}
+
+			diag("unknown param %q", key)
+		}
+	}
+
+	for _, param := range schema.Params {
+		value, ok := annotation.Value(param.Name)
+		if !ok {
+			if param.Required {
+				diag("missing required param %q", param.Name)
+				continue
+			}
+
+			if param.Default == "" {
+				continue
+			}
+
+			value = rawValue(param.Default)
+			params[param.Name] = param.Default
+			paramValues[param.Name] = value
+		}
+
+		if err := param.check(value); err != nil {
+			diag("%s", err)
+		}
+	}
+
+	return annotation, diagnostics
+}
+
+// hasParam returns true/false if the schema declares the param, matched as done by
+// AnnotationDeclaration.Value.
+func (schema AnnotationSchema) hasParam(key string) bool {
+	for _, name := range paramKeys(key) {
+		if _, ok := schema.Param(name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check returns an error if the value is not of the type of the param or not within it's enum.
+func (param ParamSchema) check(value AnnotationValue) error {
+	var err error
+
+	switch param.Type {
+	case ParamString:
+		if value.Kind != StringValue && value.Kind != IdentValue {
+			err = fmt.Errorf("param %q must be a string, got %s", param.Name, value.Raw)
+		}
+	case ParamInt:
+		if _, ierr := value.Int(); ierr != nil {
+			err = fmt.Errorf("param %q must be a int, got %s", param.Name, value.Raw)
+		}
+	case ParamFloat:
+		if _, ferr := value.Float(); ferr != nil {
+			err = fmt.Errorf("param %q must be a float, got %s", param.Name, value.Raw)
+		}
+	case ParamBool:
+		if _, berr := value.Bool(); berr != nil {
+			err = fmt.Errorf("param %q must be a bool, got %s", param.Name, value.Raw)
+		}
+	case ParamList:
+		if value.Kind != ListValue {
+			err = fmt.Errorf("param %q must be a list, got %s", param.Name, value.Raw)
+		}
+	case ParamMap:
+		if value.Kind != MapValue {
+			err = fmt.Errorf("param %q must be a map, got %s", param.Name, value.Raw)
+		}
+	}
+
+	if err != nil || len(param.Enum) == 0 {
+		return err
+	}
+
+	for _, allowed := range param.Enum {
+		if value.Text == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("param %q must be one of %s, got %s", param.Name, strings.Join(param.Enum, ", "), value.Raw)
+}
+
+// nearestParam returns the name of the param nearest to the giving key, if any is near enough
+// to be a misspelling of it.
+func (schema AnnotationSchema) nearestParam(key string) string {
+	var nearest string
+	var distance int
+	for _, param := range schema.Params {
+		current := editDistance(strings.ToLower(key), strings.ToLower(param.Name))
+		if current > maxEditDistance(key) {
+			continue
+		}
+
+		if nearest == "" || current < distance || (current == distance && param.Name < nearest) {
+			nearest, distance = param.Name, current
+		}
+	}
+
+	return nearest
+}
+
+//===========================================================================================================
+
+// RegisterSchema adds the schema for the annotation into the registry, which applies to the
+// generators of the annotation for all kinds of declarations.
+func (a *AnnotationRegistry) RegisterSchema(annotation string, schema AnnotationSchema) {
+	annotation = strings.TrimPrefix(annotation, "@")
+	a.ml.Lock()
+	{
+		a.schemas[annotation] = schema
+	}
+	a.ml.Unlock()
+}
+
+// RegisterWithSchema adds the generator as done by Register, with the schema of the annotation.
+func (a *AnnotationRegistry) RegisterWithSchema(name string, generator interface{}, schema AnnotationSchema) error {
+	if err := a.Register(name, generator); err != nil {
+		return err
+	}
+
+	a.RegisterSchema(name, schema)
+	return nil
+}
+
+// GetSchema returns the schema registered for the annotation.
+func (a *AnnotationRegistry) GetSchema(annotation string) (AnnotationSchema, bool) {
+	annotation = strings.TrimPrefix(annotation, "@")
+
+	a.ml.RLock()
+	defer a.ml.RUnlock()
+
+	schema, ok := a.schemas[annotation]
+	return schema, ok
+}
+
+// applySchema validates and default-fills the annotation with it's schema if registered, returning
+// the violations as Diagnostics positioned at the annotation, else at the declaration.
+func (a *AnnotationRegistry) applySchema(annotation AnnotationDeclaration, location token.Position) (AnnotationDeclaration, error) {
+	schema, ok := a.GetSchema(annotation.Name)
+	if !ok {
+		return annotation, nil
+	}
+
+	if !annotation.Position.IsValid() {
+		annotation.Position = location
+	}
+
+	validated, diagnostics := schema.Validate(annotation)
+	if len(diagnostics) != 0 {
+		return annotation, diagnostics
+	}
+
+	return validated, nil
+}
@@ -0,0 +1,224 @@
+package ast
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Contains the kinds of declarations a annotation generator can be registered for.
+const (
+	packageKind   = "package"
+	interfaceKind = "interface"
+	structKind    = "struct"
+	functionKind  = "function"
+	typeKind      = "type"
+	variableKind  = "variable"
+)
+
+// maxSuggestions defines the maximum number of near matches suggested for a unknown annotation.
+const maxSuggestions = 3
+
+// SetStrict sets the registry into strict mode, where ParseDeclr reports the annotations which
+// have no generator registered for the kind of their declaration, suggesting near matches and the
+// other kinds a annotation is registered for. Annotations handled elsewhere can be excluded with Allow.
+func (a *AnnotationRegistry) SetStrict(strict bool) {
+	a.ml.Lock()
+	{
+		a.strict = strict
+	}
+	a.ml.Unlock()
+}
+
+// IsStrict returns true/false if the registry is in strict mode.
+func (a *AnnotationRegistry) IsStrict() bool {
+	a.ml.RLock()
+	defer a.ml.RUnlock()
+	return a.strict
+}
+
+// Allow adds the giving annotations into the allow-list of annotations which are not reported
+// in strict mode, for annotations which are intentionally handled outside of the registry.
+func (a *AnnotationRegistry) Allow(annotations ...string) {
+	a.ml.Lock()
+	{
+		for _, annotation := range annotations {
+			a.allowed[strings.TrimPrefix(annotation, "@")] = true
+		}
+	}
+	a.ml.Unlock()
+}
+
+// IsAllowed returns true/false if the giving annotation is within the allow-list.
+func (a *AnnotationRegistry) IsAllowed(annotation string) bool {
+	a.ml.RLock()
+	defer a.ml.RUnlock()
+	return a.allowed[strings.TrimPrefix(annotation, "@")]
+}
+
+// unknownAnnotation returns a Diagnostic for a annotation with no generator for the giving kind of
+// declaration, if the registry is in strict mode and the annotation is not allowed.
+func (a *AnnotationRegistry) unknownAnnotation(kind string, annotation AnnotationDeclaration, location token.Position) (Diagnostic, bool) {
+	name := strings.TrimPrefix(annotation.Name, "@")
+
+	if !a.IsStrict() || a.IsAllowed(name) {
+		return Diagnostic{}, false
+	}
+
+	position := annotation.Position
+	if !position.IsValid() {
+		position = location
+	}
+
+	diag := Diagnostic{
+		Severity:   SeverityError,
+		Position:   position,
+		Annotation: annotation.Name,
+	}
+
+	kinds := a.registeredNames()
+
+	if others := kinds[name]; len(others) != 0 {
+		diag.Message = fmt.Sprintf("no %s generator registered, annotation is registered for %s declarations", kind, strings.Join(others, ", "))
+		return diag, true
+	}
+
+	suggestions := suggestAnnotations(name, kinds)
+	if len(suggestions) == 0 {
+		diag.Message = fmt.Sprintf("unknown %s annotation", kind)
+		return diag, true
+	}
+
+	diag.Message = fmt.Sprintf("unknown %s annotation, did you mean %s?", kind, strings.Join(suggestions, " or "))
+	return diag, true
+}
+
+// registeredNames returns the kinds of declarations each annotation has a generator registered for.
+func (a *AnnotationRegistry) registeredNames() map[string][]string {
+	a.ml.RLock()
+	defer a.ml.RUnlock()
+
+	kinds := make(map[string][]string)
+
+	for name := range a.pkgAnnotations {
+		kinds[name] = append(kinds[name], packageKind)
+	}
+
+	for name := range a.interfaceAnnotations {
+		kinds[name] = append(kinds[name], interfaceKind)
+	}
+
+	for name := range a.structAnnotations {
+		kinds[name] = append(kinds[name], structKind)
+	}
+
+	for name := range a.functionAnnotations {
+		kinds[name] = append(kinds[name], functionKind)
+	}
+
+	for name := range a.typeAnnotations {
+		kinds[name] = append(kinds[name], typeKind)
+	}
+
+	for name := range a.variableAnnotations {
+		kinds[name] = append(kinds[name], variableKind)
+	}
+
+	return kinds
+}
+
+// suggestAnnotations returns the registered annotations nearest to the giving name with the kinds
+// they are registered for, e.g `@mongo (struct)`, nearest first.
+func suggestAnnotations(name string, kinds map[string][]string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	maxDistance := maxEditDistance(name)
+
+	var matches []match
+	for registered := range kinds {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(registered)); distance <= maxDistance {
+			matches = append(matches, match{name: registered, distance: distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+
+		return matches[i].name < matches[j].name
+	})
+
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+
+	suggestions := make([]string, 0, len(matches))
+	for _, item := range matches {
+		suggestions = append(suggestions, fmt.Sprintf("@%s (%s)", item.name, strings.Join(kinds[item.name], ", ")))
+	}
+
+	return suggestions
+}
+
+// maxEditDistance returns the maximum edit distance for a name to be considered a misspelling
+// of another, allowing a edit for every three characters, at least one and at most three.
+func maxEditDistance(name string) int {
+	distance := len(name) / 3
+	if distance < 1 {
+		return 1
+	}
+
+	if distance > 3 {
+		return 3
+	}
+
+	return distance
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to turn a into b.
+func editDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	rows := make([][]int, len(ar)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(br)+1)
+		rows[i][0] = i
+	}
+
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			rows[i][j] = minOf(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				rows[i][j] = minOf(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(ar)][len(br)]
+}
+
+func minOf(first int, rest ...int) int {
+	for _, item := range rest {
+		if item < first {
+			first = item
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,107 @@
+package ast
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/influx6/moz/gen"
+)
+
+// TemplateAnnotation defines the name of the built-in annotation which renders it's template
+// into a file, e.g `@template(file => user_gen.go, dir => sub, {` followed by the template and `})`.
+const TemplateAnnotation = "template"
+
+// templateSchema defines the params of the TemplateAnnotation, other params are allowed so
+// they can be read by the template through `annotation`.
+var templateSchema = AnnotationSchema{
+	Description:  "Renders the template of the annotation with the declaration as data.",
+	AllowUnknown: true,
+	Params: []ParamSchema{
+		{Name: "file", Type: ParamString, Required: true, Description: "name of the file to write"},
+		{Name: "dir", Type: ParamString, Description: "directory relative to the destination to write the file into"},
+	},
+}
+
+// RegisterTemplate adds the generators of the TemplateAnnotation for packages, structs, interfaces,
+// types and functions into the registry, which has none until it's called.
+func (a *AnnotationRegistry) RegisterTemplate() {
+	a.RegisterPackage(TemplateAnnotation, TemplatePackageGenerator)
+	a.RegisterStructType(TemplateAnnotation, TemplateStructGenerator)
+	a.RegisterInterfaceType(TemplateAnnotation, TemplateInterfaceGenerator)
+	a.RegisterType(TemplateAnnotation, TemplateTypeGenerator)
+	a.RegisterFunctionType(TemplateAnnotation, TemplateFunctionGenerator)
+	a.RegisterSchema(TemplateAnnotation, templateSchema)
+}
+
+// TemplatePackageGenerator renders the template of the annotation with the PackageDeclaration as data.
+func TemplatePackageGenerator(toDir string, an AnnotationDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+	return renderTemplate(an, pkgDeclr, pkgDeclr, pkg)
+}
+
+// TemplateStructGenerator renders the template of the annotation with the StructDeclaration as data.
+func TemplateStructGenerator(toDir string, an AnnotationDeclaration, str StructDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+	return renderTemplate(an, str, pkgDeclr, pkg)
+}
+
+// TemplateInterfaceGenerator renders the template of the annotation with the InterfaceDeclaration as data.
+func TemplateInterfaceGenerator(toDir string, an AnnotationDeclaration, inter InterfaceDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+	return renderTemplate(an, inter, pkgDeclr, pkg)
+}
+
+// TemplateTypeGenerator renders the template of the annotation with the TypeDeclaration as data.
+func TemplateTypeGenerator(toDir string, an AnnotationDeclaration, typ TypeDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+	return renderTemplate(an, typ, pkgDeclr, pkg)
+}
+
+// TemplateFunctionGenerator renders the template of the annotation with the FuncDeclaration as data.
+func TemplateFunctionGenerator(toDir string, an AnnotationDeclaration, fun FuncDeclaration, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+	return renderTemplate(an, fun, pkgDeclr, pkg)
+}
+
+// renderTemplate renders the template of the annotation with the declaration as data, using the
+// functions of ASTTemplatFuncs and the gen package, with `annotation`, `packageDeclr` and `package`
+// returning the annotation and the package of the declaration. The template is rendered when the
+// directive is created, so errors are reported at the annotation.
+func renderTemplate(an AnnotationDeclaration, declaration interface{}, pkgDeclr PackageDeclaration, pkg Package) ([]gen.WriteDirective, error) {
+	fileName, _ := an.StringParam("file")
+	if fileName == "" {
+		return nil, errors.New(`missing required param "file"`)
+	}
+
+	if strings.TrimSpace(an.Template) == "" {
+		return nil, errors.New("annotation has no template to render")
+	}
+
+	funcs := gen.ToTemplateFuncs(ASTTemplatFuncs, map[string]interface{}{
+		"annotation": func() AnnotationDeclaration {
+			return an
+		},
+		"packageDeclr": func() PackageDeclaration {
+			return pkgDeclr
+		},
+		"package": func() Package {
+			return pkg
+		},
+	})
+
+	tml, err := gen.ToTemplate(fileName, an.Template, funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	var bu bytes.Buffer
+	if err := tml.Execute(&bu, declaration); err != nil {
+		return nil, err
+	}
+
+	dir, _ := an.StringParam("dir")
+
+	return []gen.WriteDirective{
+		{
+			Dir:      dir,
+			FileName: fileName,
+			Writer:   gen.Text(bu.String()),
+		},
+	}, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+// generate runs all registered generators against the annotations found within
+// the package, writing the output into the destination directory or printing the
+// plan of files to be written if in dry-run mode.
+func generate(args []string) error {
+	var pf packageFlags
+	var outDir, allow, options string
+	var overwrite, dryRun, format, strict, iterate, keepGoing, allOrNothing bool
+	var maxIterations, workers int
+
+	set := flag.NewFlagSet("generate", flag.ExitOnError)
+	set.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: moz generate [flags] [dir]\n\nFlags:\n")
+		set.PrintDefaults()
+	}
+
+	pf.register(set)
+	set.StringVar(&outDir, "out", "", "destination directory for generated files, defaults to package directory")
+	set.BoolVar(&overwrite, "overwrite", false, "overwrite existing files even if generator marks them as DontOverride")
+	set.BoolVar(&dryRun, "dry-run", false, "print the files that would be written without touching the disk")
+	set.BoolVar(&format, "format", false, "gofmt generated .go files, removing unused and adding missing standard library imports")
+	set.BoolVar(&strict, "strict", false, "fail on annotations with no generator registered for their declaration")
+	set.StringVar(&allow, "allow", "", "comma or space separated list of annotations handled elsewhere, which -strict does not report")
+	set.BoolVar(&iterate, "iterate", false, "re-parse generated files and run generators on their annotations until no new output is produced")
+	set.StringVar(&options, "options", "", "comma or space separated list of key=value options provided to generators")
+	set.IntVar(&workers, "workers", runtime.NumCPU(), "number of declarations whose generators run concurrently")
+	set.BoolVar(&keepGoing, "keep-going", false, "run all generators when some fail, writing the output of the others and reporting all failures")
+	set.BoolVar(&allOrNothing, "all-or-nothing", false, "with -keep-going, write nothing if any generator fails")
+	set.IntVar(&maxIterations, "max-iterations", ast.DefaultMaxIterations, "maximum number of generation iterations with -iterate")
+	set.Parse(args)
+
+	registry.SetStrict(strict)
+	registry.Allow(splitList(allow)...)
+
+	log := metrics.New()
+
+	dir, pkgs, err := pf.load(log, set.Args())
+	if err != nil {
+		return err
+	}
+
+	if outDir == "" {
+		outDir = dir
+	}
+
+	if outDir, err = filepath.Abs(outDir); err != nil {
+		return err
+	}
+
+	// Interrupting stops generators yet to run, as they are cancelled with the context.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	config := ast.ParseConfig{
+		Context:      ctx,
+		Dir:          outDir,
+		Workers:      workers,
+		KeepGoing:    keepGoing,
+		AllOrNothing: allOrNothing,
+	}
+
+	if config.Options, err = parseOptions(options); err != nil {
+		return err
+	}
+
+	if iterate {
+		if dryRun {
+			return errors.New("-iterate writes generated files to re-parse them, it can not be used with -dry-run")
+		}
+
+		return iterateUntilStable(log, outDir, overwrite, ast.FixpointOptions{
+			MaxIterations: maxIterations,
+			Format:        format,
+			Load:          pf.loader(),
+			Config:        config,
+		}, pkgs)
+	}
+
+	// With -keep-going, the directives of successful generators are written before
+	// the failures of the others are reported.
+	directives, err := directivesFor(config, format, pkgs)
+	failures, failed := err.(ast.GenerationErrors)
+	if err != nil && !failed {
+		return err
+	}
+
+	if dryRun {
+		if err := plan(os.Stdout, outDir, overwrite, directives); err != nil {
+			return err
+		}
+	} else {
+		for _, directive := range directives {
+			if err := ast.WriteDirective(log, outDir, overwrite, directive.WriteDirective); err != nil {
+				return fmt.Errorf("@%s: %s", directive.Annotation, err)
+			}
+		}
+	}
+
+	if failed {
+		return failures
+	}
+
+	return nil
+}
+
+// iterateUntilStable runs the generators of the packages until the files they generate
+// produce no new output, printing the number of iterations run.
+func iterateUntilStable(log metrics.Metrics, toDir string, overwrite bool, options ast.FixpointOptions, pkgs ast.Packages) error {
+	iterations, err := ast.ParseUntilStable(toDir, log, registry, overwrite, options, pkgs...)
+	if err != nil {
+		return relativeError(err)
+	}
+
+	fmt.Printf("Generation stable after %d iteration(s).\n", iterations)
+	return nil
+}
+
+// parseOptions returns the options of the comma or space separated list of key=value pairs.
+func parseOptions(list string) (map[string]string, error) {
+	options := make(map[string]string)
+	for _, item := range splitList(list) {
+		index := strings.Index(item, "=")
+		if index < 1 {
+			return nil, fmt.Errorf("option %q must be a key=value pair", item)
+		}
+
+		options[item[:index]] = item[index+1:]
+	}
+
+	return options, nil
+}
+
+// directivesFor returns the WriteDirectives generated for all packages into the directory
+// of the config, with the deferred annotations run after all others. When keeping going,
+// the directives of successful generators are returned with the GenerationErrors.
+func directivesFor(config ast.ParseConfig, format bool, pkgs ast.Packages) ([]ast.AnnotationWriteDirective, error) {
+	toPath, err := ast.ImportPathFor(config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("Destination path is not within a go module or current GOPATH: %+q", err.Error())
+	}
+
+	directives, err := registry.ParsePackagesWith(config, pkgs, toPath)
+	if _, ok := err.(ast.GenerationErrors); err != nil && !ok {
+		return nil, relativeError(err)
+	}
+
+	for index := range directives {
+		directives[index].Format = directives[index].Format || format
+	}
+
+	if err != nil {
+		return directives, relativeError(err)
+	}
+
+	return directives, nil
+}
+
+// plan writes into w the list of WriteDirectives, detailing the annotation, the
+// file it was found in and the destination of the directive.
+func plan(w io.Writer, toDir string, overwrite bool, directives []ast.AnnotationWriteDirective) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ANNOTATION\tSOURCE\tDESTINATION\tACTION\n")
+
+	for _, directive := range directives {
+		destination := filepath.Join(toDir, directive.Dir, directive.FileName)
+		if rel, err := filepath.Rel(toDir, destination); err == nil {
+			destination = rel
+		}
+
+		fmt.Fprintf(tw, "@%s\t%s\t%s\t%s\n", directive.Annotation, filepath.Base(directive.Source), destination, planAction(toDir, overwrite, directive.WriteDirective))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d directive(s) for %q, nothing written.\n", len(directives), toDir)
+	return err
+}
+
+// planAction returns the action ast.WriteDirective would take for the giving directive.
+func planAction(toDir string, overwrite bool, directive gen.WriteDirective) string {
+	if directive.Writer == nil {
+		return "mkdir"
+	}
+
+	destination := filepath.Join(toDir, directive.Dir, directive.FileName)
+
+	if directive.Format && filepath.Ext(directive.FileName) == ".go" {
+		var src bytes.Buffer
+		if _, err := directive.Writer.WriteTo(&src); err != nil && err != io.EOF {
+			return "error: " + err.Error()
+		}
+
+		if _, err := gen.FormatSource(destination, src.Bytes()); err != nil {
+			return "error: " + err.Error()
+		}
+	}
+
+	stat, err := os.Stat(destination)
+	switch {
+	case err != nil:
+		return "create"
+	case stat.IsDir():
+		return "error: destination is a directory"
+	case directive.DontOverride && !overwrite:
+		return "skip (exists)"
+	default:
+		return "overwrite"
+	}
+}
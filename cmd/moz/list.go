@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/moz/ast"
+)
+
+// listAnnotations prints all annotations found on the declarations within
+// the package, including if a generator is registered for them.
+func listAnnotations(args []string) error {
+	var pf packageFlags
+	var withTests bool
+
+	set := flag.NewFlagSet("list-annotations", flag.ExitOnError)
+	set.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: moz list-annotations [flags] [dir]\n\nFlags:\n")
+		set.PrintDefaults()
+	}
+
+	pf.register(set)
+	set.BoolVar(&withTests, "tests", false, "include annotations found in test files")
+	set.Parse(args)
+
+	_, pkgs, err := pf.load(metrics.New(), set.Args())
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "POSITION\tKIND\tDECLARATION\tANNOTATION\tGENERATOR\n")
+
+	for _, pkg := range pkgs {
+		for _, declr := range pkg.Packages {
+			listDeclaration(tw, declr)
+		}
+
+		if !withTests {
+			continue
+		}
+
+		for _, declr := range pkg.TestPackages {
+			listDeclaration(tw, declr)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// listDeclaration writes a line into w for each annotation found within the package declaration.
+func listDeclaration(w io.Writer, declr ast.PackageDeclaration) {
+	for _, annotation := range declr.Annotations {
+		_, err := registry.GetPackage(annotation.Name)
+		listAnnotation(w, declr, 0, "package", declr.Package, annotation, err == nil)
+	}
+
+	for _, inter := range declr.Interfaces {
+		for _, annotation := range inter.Annotations {
+			_, err := registry.GetInterfaceType(annotation.Name)
+			listAnnotation(w, declr, inter.From, "interface", inter.Name, annotation, err == nil)
+		}
+	}
+
+	for _, str := range declr.Structs {
+		for _, annotation := range str.Annotations {
+			_, err := registry.GetStructType(annotation.Name)
+			listAnnotation(w, declr, str.From, "struct", str.Name, annotation, err == nil)
+		}
+	}
+
+	for _, fn := range declr.Functions {
+		for _, annotation := range fn.Annotations {
+			_, err := registry.GetFunctionType(annotation.Name)
+			listAnnotation(w, declr, fn.From, "function", fn.FuncName, annotation, err == nil)
+		}
+	}
+
+	for _, typ := range declr.Types {
+		for _, annotation := range typ.Annotations {
+			_, err := registry.GetType(annotation.Name)
+			listAnnotation(w, declr, typ.From, "type", typ.Name, annotation, err == nil)
+		}
+	}
+
+	for _, variable := range declr.Variables {
+		for _, annotation := range variable.Annotations {
+			_, err := registry.GetVariableType(annotation.Name)
+			listAnnotation(w, declr, variable.From, "variable", variable.Name, annotation, err == nil)
+		}
+	}
+}
+
+func listAnnotation(w io.Writer, declr ast.PackageDeclaration, offset int, kind string, name string, annotation ast.AnnotationDeclaration, registered bool) {
+	generator := "none"
+	if registered {
+		generator = "registered"
+	}
+
+	fmt.Fprintf(w, "%s:%d\t%s\t%s\t%s\t%s\n", filepath.Base(declr.FilePath), lineAt(declr.Source, offset), kind, name, formatAnnotation(annotation), generator)
+}
+
+// formatAnnotation returns the annotation in the form it was declared, omitting any template.
+func formatAnnotation(annotation ast.AnnotationDeclaration) string {
+	name := "@" + strings.TrimPrefix(annotation.Name, "@")
+	if len(annotation.Arguments) == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s(%s)", name, strings.Join(annotation.Arguments, ", "))
+}
+
+// lineAt returns the line number within source for the giving byte offset.
+func lineAt(source string, offset int) int {
+	if offset > len(source) {
+		offset = len(source)
+	}
+
+	return strings.Count(source[:offset], "\n") + 1
+}
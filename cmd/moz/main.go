@@ -0,0 +1,214 @@
+// Package main provides the moz command which runs annotation generators
+// against the Go package found within a giving directory.
+//
+//	moz generate [flags] [dir]
+//	moz list-annotations [flags] [dir]
+//
+// It is commonly used through a `//go:generate moz generate` directive.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/plugins"
+)
+
+// registry contains all annotation generators available to the moz command, which
+// includes the built-in @template generator and the plugins registered in the plugin
+// config file.
+var registry = newRegistry()
+
+// newRegistry returns the registry of the moz command, with the @template annotation
+// registered so templates can be generated without writing any Go code.
+func newRegistry() *ast.AnnotationRegistry {
+	registry := ast.NewAnnotationRegistry()
+	registry.RegisterTemplate()
+	return registry
+}
+
+var usage = `Usage: moz <command> [flags] [dir]
+
+Commands:
+  generate          runs all registered generators for annotations found in package
+  list-annotations  lists the annotations found on each declaration in package
+
+The package directory defaults to the current working directory.
+Run 'moz <command> -h' for the flags of a command.
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "generate":
+		err = generate(args)
+	case "list-annotations", "list":
+		err = listAnnotations(args)
+	case "help":
+		flag.Usage()
+	default:
+		fmt.Fprintf(os.Stderr, "moz: unknown command %q\n\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "moz: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// packageFlags defines the flags shared by all commands for loading a package.
+type packageFlags struct {
+	tags   string
+	config string
+	typed  bool
+}
+
+func (pf *packageFlags) register(set *flag.FlagSet) {
+	set.StringVar(&pf.tags, "tags", "", "comma or space separated list of build tags to apply when loading package")
+	set.StringVar(&pf.config, "config", "", "plugin config file, defaults to the nearest "+plugins.ConfigFile+" from the package directory")
+	set.BoolVar(&pf.typed, "typed", false, "type check the package, providing generators with go/types information")
+}
+
+// load returns the packages found within the directory provided in args, using the
+// current working directory if none is provided.
+func (pf *packageFlags) load(log metrics.Metrics, args []string) (string, ast.Packages, error) {
+	if len(args) > 1 {
+		return "", nil, fmt.Errorf("expected a single package directory, got %d", len(args))
+	}
+
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := pf.registerPlugins(dir, splitList(pf.tags)); err != nil {
+		return dir, nil, err
+	}
+
+	pkgs, err := pf.loader()(log, dir)
+	if err != nil {
+		return dir, nil, fmt.Errorf("failed to load package at %q: %s", dir, err)
+	}
+
+	printDiagnostics(os.Stderr, pkgs.Diagnostics())
+
+	return dir, pkgs, nil
+}
+
+// loader returns the function loading the package of a directory with the build tags
+// and type checking of the flags.
+func (pf *packageFlags) loader() ast.PackageLoader {
+	ctx := build.Default
+	ctx.BuildTags = splitList(pf.tags)
+
+	load := ast.FilteredPackageWithBuildCtx
+	if pf.typed {
+		load = ast.TypedPackageWithBuildCtx
+	}
+
+	return func(log metrics.Metrics, dir string) (ast.Packages, error) {
+		return load(log, dir, ctx)
+	}
+}
+
+// printDiagnostics writes into w the diagnostics, one per line, with their files
+// relative to the current working directory.
+func printDiagnostics(w io.Writer, ds ast.Diagnostics) {
+	for _, diag := range ds {
+		fmt.Fprintln(w, relativeDiagnostic(diag))
+	}
+}
+
+// relativeDiagnostic returns the diagnostic with it's file relative to the current
+// working directory, if the file is within it.
+func relativeDiagnostic(diag ast.Diagnostic) ast.Diagnostic {
+	wd, err := os.Getwd()
+	if err != nil || diag.Position.Filename == "" {
+		return diag
+	}
+
+	if rel, err := filepath.Rel(wd, diag.Position.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+		diag.Position.Filename = rel
+	}
+
+	return diag
+}
+
+// relativeError returns the error with the files of it's diagnostics relative to the current
+// working directory, if it is a diagnostic or contains them.
+func relativeError(err error) error {
+	switch derr := err.(type) {
+	case ast.Diagnostic:
+		return relativeDiagnostic(derr)
+	case ast.Diagnostics:
+		for index, diag := range derr {
+			derr[index] = relativeDiagnostic(diag)
+		}
+
+		return derr
+	case ast.GenerationErrors:
+		for index, failure := range derr {
+			derr[index].Diagnostic = relativeDiagnostic(failure.Diagnostic)
+		}
+
+		return derr
+	}
+
+	return err
+}
+
+// registerPlugins adds the plugins listed in the config file into the registry.
+func (pf *packageFlags) registerPlugins(dir string, tags []string) error {
+	var config plugins.Config
+	var err error
+
+	if pf.config != "" {
+		config, err = plugins.LoadConfig(pf.config)
+	} else {
+		config, err = plugins.FindConfig(dir)
+	}
+
+	if err == plugins.ErrNoConfig {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	config.Register(registry, tags)
+	return nil
+}
+
+// splitList returns the items within the giving comma or space separated list.
+func splitList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
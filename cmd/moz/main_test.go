@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+)
+
+var templateSource = `package mock
+
+// User defines a user of the service.
+// @template(file => user_fields.go, dir => fields, {
+//   package fields
+//
+//   // Fields contains the fields of {{.Name}}.
+//   var Fields = []string{ {{range getFields . .Declr}}"{{.FieldName}}", {{end}}}
+// })
+type User struct {
+	Name  string
+	Email string
+}
+`
+
+// writeTemplatePackage writes a module with a package annotated with @template into a
+// temporary directory, returning the directory.
+func writeTemplatePackage(t *testing.T) string {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":  "module example.com/mock\n",
+		"mock.go": templateSource,
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			tests.Failed("Should have written file %q: %+q", name, err)
+		}
+	}
+
+	return dir
+}
+
+// TestGenerateTemplate validates the generation of @template annotations by the moz command.
+func TestGenerateTemplate(t *testing.T) {
+	dir := writeTemplatePackage(t)
+
+	if err := generate([]string{dir}); err != nil {
+		tests.Failed("Should have generated @template annotation: %+q", err)
+	}
+	tests.Passed("Should have generated @template annotation")
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "fields", "user_fields.go"))
+	if err != nil {
+		tests.Failed("Should have written rendered template: %+q", err)
+	}
+	tests.Passed("Should have written rendered template")
+
+	if !strings.Contains(string(content), `var Fields = []string{ "Name", "Email", }`) {
+		tests.Info("Rendered: %q", content)
+		tests.Failed("Should have rendered template with User struct as data")
+	}
+	tests.Passed("Should have rendered template with User struct as data")
+}
+
+// TestListTemplate validates the listing of @template annotations as having a generator.
+func TestListTemplate(t *testing.T) {
+	dir := writeTemplatePackage(t)
+
+	out, err := os.Create(filepath.Join(t.TempDir(), "list.txt"))
+	if err != nil {
+		tests.Failed("Should have created output file: %+q", err)
+	}
+	defer out.Close()
+
+	stdout := os.Stdout
+	os.Stdout = out
+	err = listAnnotations([]string{dir})
+	os.Stdout = stdout
+
+	if err != nil {
+		tests.Failed("Should have listed annotations: %+q", err)
+	}
+	tests.Passed("Should have listed annotations")
+
+	content, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		tests.Failed("Should have read listed annotations: %+q", err)
+	}
+
+	if !strings.Contains(string(content), "@template(file => user_fields.go, dir => fields)") || !strings.Contains(string(content), "registered") {
+		tests.Info("Listed: %s", content)
+		tests.Failed("Should have listed @template annotation with it's generator")
+	}
+	tests.Passed("Should have listed @template annotation with it's generator")
+}
@@ -0,0 +1,501 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StdlibImports maps the package names of the standard library to their import path, which is used
+// by FormatSource to add missing imports. Names shared by multiple packages (e.g rand, template) are
+// left out as they can not be resolved without ambiguity.
+var StdlibImports = map[string]string{
+	"adler32":   "hash/adler32",
+	"aes":       "crypto/aes",
+	"ascii85":   "encoding/ascii85",
+	"asn1":      "encoding/asn1",
+	"ast":       "go/ast",
+	"atomic":    "sync/atomic",
+	"base32":    "encoding/base32",
+	"base64":    "encoding/base64",
+	"big":       "math/big",
+	"binary":    "encoding/binary",
+	"bits":      "math/bits",
+	"bufio":     "bufio",
+	"build":     "go/build",
+	"bytes":     "bytes",
+	"bzip2":     "compress/bzip2",
+	"cipher":    "crypto/cipher",
+	"cmplx":     "math/cmplx",
+	"context":   "context",
+	"crc32":     "hash/crc32",
+	"crc64":     "hash/crc64",
+	"crypto":    "crypto",
+	"csv":       "encoding/csv",
+	"debug":     "runtime/debug",
+	"des":       "crypto/des",
+	"driver":    "database/sql/driver",
+	"ecdsa":     "crypto/ecdsa",
+	"ed25519":   "crypto/ed25519",
+	"elliptic":  "crypto/elliptic",
+	"errors":    "errors",
+	"exec":      "os/exec",
+	"expvar":    "expvar",
+	"filepath":  "path/filepath",
+	"flag":      "flag",
+	"flate":     "compress/flate",
+	"fmt":       "fmt",
+	"fnv":       "hash/fnv",
+	"format":    "go/format",
+	"gob":       "encoding/gob",
+	"gzip":      "compress/gzip",
+	"hash":      "hash",
+	"heap":      "container/heap",
+	"hex":       "encoding/hex",
+	"hmac":      "crypto/hmac",
+	"html":      "html",
+	"http":      "net/http",
+	"httptest":  "net/http/httptest",
+	"httputil":  "net/http/httputil",
+	"image":     "image",
+	"io":        "io",
+	"ioutil":    "io/ioutil",
+	"json":      "encoding/json",
+	"list":      "container/list",
+	"log":       "log",
+	"mail":      "net/mail",
+	"math":      "math",
+	"md5":       "crypto/md5",
+	"mime":      "mime",
+	"multipart": "mime/multipart",
+	"net":       "net",
+	"os":        "os",
+	"parser":    "go/parser",
+	"path":      "path",
+	"pem":       "encoding/pem",
+	"pprof":     "runtime/pprof",
+	"printer":   "go/printer",
+	"quick":     "testing/quick",
+	"reflect":   "reflect",
+	"regexp":    "regexp",
+	"ring":      "container/ring",
+	"rpc":       "net/rpc",
+	"rsa":       "crypto/rsa",
+	"runtime":   "runtime",
+	"scanner":   "text/scanner",
+	"sha1":      "crypto/sha1",
+	"sha256":    "crypto/sha256",
+	"sha512":    "crypto/sha512",
+	"signal":    "os/signal",
+	"smtp":      "net/smtp",
+	"sort":      "sort",
+	"sql":       "database/sql",
+	"strconv":   "strconv",
+	"strings":   "strings",
+	"subtle":    "crypto/subtle",
+	"sync":      "sync",
+	"syscall":   "syscall",
+	"tabwriter": "text/tabwriter",
+	"tar":       "archive/tar",
+	"testing":   "testing",
+	"textproto": "net/textproto",
+	"time":      "time",
+	"tls":       "crypto/tls",
+	"token":     "go/token",
+	"types":     "go/types",
+	"unicode":   "unicode",
+	"unsafe":    "unsafe",
+	"url":       "net/url",
+	"user":      "os/user",
+	"utf16":     "unicode/utf16",
+	"utf8":      "unicode/utf8",
+	"x509":      "crypto/x509",
+	"xml":       "encoding/xml",
+	"zip":       "archive/zip",
+	"zlib":      "compress/zlib",
+}
+
+// FormatError defines the error returned when the source provided to FormatSource is not valid Go.
+type FormatError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error returns the error in the form `file:line:column: message`.
+func (fe *FormatError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", fe.File, fe.Line, fe.Column, fe.Err.Error())
+}
+
+// FormatSource formats the giving Go source as gofmt would, after removing the imports which are
+// not used within the source and adding the standard library imports which are used but missing.
+// The fileName is used to report the position of parse errors with a FormatError.
+func FormatSource(fileName string, src []byte) ([]byte, error) {
+	tokenFiles := token.NewFileSet()
+
+	file, err := parser.ParseFile(tokenFiles, fileName, src, parser.ParseComments)
+	if err != nil {
+		return nil, toFormatError(fileName, err)
+	}
+
+	src, _ = fixImports(tokenFiles, file, src)
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, toFormatError(fileName, err)
+	}
+
+	return formatted, nil
+}
+
+// Formatted returns a io.WriterTo which writes the output of the provided writer formatted
+// with FormatSource.
+func Formatted(fileName string, writer io.WriterTo) io.WriterTo {
+	return formattedWriter{fileName: fileName, writer: writer}
+}
+
+type formattedWriter struct {
+	fileName string
+	writer   io.WriterTo
+}
+
+// WriteTo writes the formatted output of the underline writer into w.
+func (fw formattedWriter) WriteTo(w io.Writer) (int64, error) {
+	var src bytes.Buffer
+	if _, err := fw.writer.WriteTo(&src); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	formatted, err := FormatSource(fw.fileName, src.Bytes())
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := w.Write(formatted)
+	return int64(written), err
+}
+
+// fixImports returns the source with unused imports removed and missing standard library
+// imports added. Imports are edited in place, keeping their grouping and comments, and only
+// those whose name is known (see ImportName) are removed. It returns false if the imports
+// required no change.
+func fixImports(tokenFiles *token.FileSet, file *ast.File, src []byte) ([]byte, bool) {
+	unresolved := make(map[*ast.Ident]bool)
+	for _, ident := range file.Unresolved {
+		unresolved[ident] = true
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := selector.X.(*ast.Ident); ok && unresolved[ident] {
+			used[ident.Name] = true
+		}
+
+		return true
+	})
+
+	offset := func(pos token.Pos) int {
+		return tokenFiles.Position(pos).Offset
+	}
+
+	// lineOf extends the range to the whole lines it spans if nothing else is on them.
+	lineOf := func(start, end int) (int, int) {
+		lineStart := start
+		for lineStart > 0 && (src[lineStart-1] == ' ' || src[lineStart-1] == '\t') {
+			lineStart--
+		}
+
+		lineEnd := end
+		for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t') {
+			lineEnd++
+		}
+
+		if (lineStart == 0 || src[lineStart-1] == '\n') && (lineEnd == len(src) || src[lineEnd] == '\n') {
+			if lineEnd < len(src) {
+				lineEnd++
+			}
+
+			return lineStart, lineEnd
+		}
+
+		return start, end
+	}
+
+	var edits []sourceEdit
+	var target *ast.GenDecl
+	var targetStd *ast.ImportSpec
+
+	imported := make(map[string]bool)
+
+	for _, decl := range file.Decls {
+		gdecl, ok := decl.(*ast.GenDecl)
+		if !ok || gdecl.Tok != token.IMPORT {
+			continue
+		}
+
+		var kept int
+		var removed []sourceEdit
+		var firstStd *ast.ImportSpec
+
+		for _, spec := range gdecl.Specs {
+			imp := spec.(*ast.ImportSpec)
+
+			start, end := imp.Pos(), imp.End()
+			if imp.Doc != nil {
+				start = imp.Doc.Pos()
+			}
+
+			if imp.Comment != nil {
+				end = imp.Comment.End()
+			}
+
+			name, known := ImportName(imp)
+			if !known {
+				// Not removed, but it's likely name still shadows a standard library import.
+				imported[path.Base(importPathOf(imp))] = true
+				kept++
+				continue
+			}
+
+			if name != "_" && name != "." && !used[name] {
+				from, to := lineOf(offset(start), offset(end))
+				removed = append(removed, sourceEdit{start: from, end: to})
+				continue
+			}
+
+			imported[name] = true
+			kept++
+
+			if firstStd == nil && isStdlibImport(imp.Path.Value) {
+				firstStd = imp
+			}
+		}
+
+		if kept == 0 && (!gdecl.Lparen.IsValid() || !hasComments(file, gdecl)) {
+			start := gdecl.Pos()
+			if gdecl.Doc != nil {
+				start = gdecl.Doc.Pos()
+			}
+
+			end := gdecl.End()
+			if !gdecl.Lparen.IsValid() {
+				if imp := gdecl.Specs[0].(*ast.ImportSpec); imp.Comment != nil {
+					end = imp.Comment.End()
+				}
+			}
+
+			from, to := lineOf(offset(start), offset(end))
+			edits = append(edits, sourceEdit{start: from, end: to})
+			continue
+		}
+
+		edits = append(edits, removed...)
+
+		// Missing imports are added to the first import declaration which is kept, along it's
+		// standard library imports if any.
+		if target == nil && kept != 0 {
+			target, targetStd = gdecl, firstStd
+		}
+	}
+
+	var missing []string
+	for name := range used {
+		if imported[name] {
+			continue
+		}
+
+		if importPath, ok := StdlibImports[name]; ok {
+			missing = append(missing, "\t"+strconv.Quote(importPath)+"\n")
+		}
+	}
+
+	sort.Strings(missing)
+	imports := strings.Join(missing, "")
+
+	switch {
+	case len(missing) == 0:
+	case target == nil:
+		// The import declaration is added on the line following the package clause.
+		at := offset(file.Name.End())
+		for at < len(src) && src[at] != '\n' {
+			at++
+		}
+
+		text := "\n\nimport (\n" + imports + ")"
+		if len(missing) == 1 {
+			text = "\n\nimport " + strings.TrimSpace(imports)
+		}
+
+		edits = append(edits, sourceEdit{start: at, end: at, text: text})
+	case !target.Lparen.IsValid():
+		imp := target.Specs[0].(*ast.ImportSpec)
+		end := imp.End()
+		if imp.Comment != nil {
+			end = imp.Comment.End()
+		}
+
+		spec := string(src[offset(imp.Pos()):offset(end)])
+		edits = append(edits, sourceEdit{start: offset(imp.Pos()), end: offset(end), text: "(\n" + imports + "\t" + spec + "\n)"})
+	case targetStd != nil && tokenFiles.Position(targetStd.End()).Line != tokenFiles.Position(target.Rparen).Line:
+		// Added on the line following the import, which keeps it's comments attached to it.
+		at := offset(targetStd.End())
+		for at < len(src) && src[at] != '\n' {
+			at++
+		}
+
+		if at < len(src) {
+			at++
+		}
+
+		edits = append(edits, sourceEdit{start: at, end: at, text: imports})
+	default:
+		at := offset(target.Lparen) + 1
+		edits = append(edits, sourceEdit{start: at, end: at, text: "\n" + imports + "\n"})
+	}
+
+	if len(edits) == 0 {
+		return src, false
+	}
+
+	// Insertions are applied before removals starting at the same offset.
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+
+		return edits[i].end-edits[i].start < edits[j].end-edits[j].start
+	})
+
+	var fixed bytes.Buffer
+
+	var last int
+	for _, edit := range edits {
+		fixed.Write(src[last:edit.start])
+		fixed.WriteString(edit.text)
+		last = edit.end
+	}
+
+	fixed.Write(src[last:])
+	return fixed.Bytes(), true
+}
+
+// sourceEdit defines the replacement of the bytes between start and end of a source with text.
+type sourceEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// hasComments returns true/false if the file has comments within the parentheses of the declaration
+// which are not attached to it's specs.
+func hasComments(file *ast.File, gdecl *ast.GenDecl) bool {
+	attached := make(map[*ast.CommentGroup]bool)
+	for _, spec := range gdecl.Specs {
+		imp := spec.(*ast.ImportSpec)
+		attached[imp.Doc] = true
+		attached[imp.Comment] = true
+	}
+
+	for _, group := range file.Comments {
+		if group.Pos() > gdecl.Lparen && group.End() < gdecl.Rparen && !attached[group] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStdlibImport returns true/false if the quoted import path belongs to the standard library,
+// which is assumed when the first element of the path has no dot.
+func isStdlibImport(quoted string) bool {
+	importPath, err := strconv.Unquote(quoted)
+	if err != nil {
+		importPath = quoted
+	}
+
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+// ImportName returns the name the import is referenced by within source, which is only known
+// if the import has a explicit name or belongs to the standard library, whose packages are
+// named after the last element of their path. Others may be named anything (e.g
+// github.com/json-iterator/go is referenced as jsoniter), for which false is returned.
+func ImportName(imp *ast.ImportSpec) (string, bool) {
+	if imp.Name != nil {
+		return imp.Name.Name, true
+	}
+
+	if !isStdlibImport(imp.Path.Value) {
+		return "", false
+	}
+
+	importPath := importPathOf(imp)
+
+	// Versioned paths (e.g math/rand/v2) are referenced by the name before the version.
+	name := path.Base(importPath)
+	if isMajorVersion(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	return name, true
+}
+
+// importPathOf returns the unquoted path of the import.
+func importPathOf(imp *ast.ImportSpec) string {
+	importPath, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return imp.Path.Value
+	}
+
+	return importPath
+}
+
+func isMajorVersion(name string) bool {
+	if len(name) < 2 || name[0] != 'v' {
+		return false
+	}
+
+	_, err := strconv.Atoi(name[1:])
+	return err == nil
+}
+
+// toFormatError returns the first error within a parse error as a FormatError.
+func toFormatError(fileName string, err error) error {
+	switch perr := err.(type) {
+	case scanner.ErrorList:
+		if len(perr) == 0 {
+			return err
+		}
+
+		return &FormatError{
+			File:   fileName,
+			Line:   perr[0].Pos.Line,
+			Column: perr[0].Pos.Column,
+			Err:    fmt.Errorf("%s", perr[0].Msg),
+		}
+	case *scanner.Error:
+		return &FormatError{
+			File:   fileName,
+			Line:   perr.Pos.Line,
+			Column: perr.Pos.Column,
+			Err:    fmt.Errorf("%s", perr.Msg),
+		}
+	default:
+		return fmt.Errorf("%s: %s", fileName, err.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package gen_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/moz/gen"
+)
+
+// TestFormatSource validates the formatting and import fixing of generated source.
+func TestFormatSource(t *testing.T) {
+	src := "// Package mock does.\npackage mock\n\nimport (\n\t\"os\" // unused\n\t\"fmt\"\n\n\t\"github.com/influx6/faux/metrics\"\n)\n\n// Lower does.\nfunc Lower(m metrics.Metrics) string {   return fmt.Sprint(strings.ToLower(\"X\"))\n}\n"
+	expected := "// Package mock does.\npackage mock\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n\n\t\"github.com/influx6/faux/metrics\"\n)\n\n// Lower does.\nfunc Lower(m metrics.Metrics) string {\n\treturn fmt.Sprint(strings.ToLower(\"X\"))\n}\n"
+
+	formatted, err := gen.FormatSource("mock.go", []byte(src))
+	if err != nil {
+		tests.Failed("Should have successfully formatted source: %+q.", err)
+	}
+	tests.Passed("Should have successfully formatted source.")
+
+	if string(formatted) != expected {
+		tests.Info("Source: %+q", string(formatted))
+		tests.Info("Expected: %+q", expected)
+		tests.Failed("Should have removed unused and added missing imports.")
+	}
+	tests.Passed("Should have removed unused and added missing imports.")
+}
+
+// TestFormatSourceWithoutImports validates the addition of a import block to source without one.
+func TestFormatSourceWithoutImports(t *testing.T) {
+	expected := "package mock\n\nimport \"errors\"\n\nvar ErrMock = errors.New(\"mock\")\n"
+
+	var bu bytes.Buffer
+	if _, err := gen.Formatted("mock.go", gen.Text("package mock\nvar ErrMock = errors.New(\"mock\")")).WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully formatted source: %+q.", err)
+	}
+	tests.Passed("Should have successfully formatted source.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+		tests.Failed("Should have added missing import.")
+	}
+	tests.Passed("Should have added missing import.")
+}
+
+// TestFormatSourceWithInvalidSource validates the error returned for invalid source.
+func TestFormatSourceWithInvalidSource(t *testing.T) {
+	_, err := gen.FormatSource("mock.go", []byte("package mock\n\nfunc Mock() {\n\treturn 1 +\n}\n"))
+	if err == nil {
+		tests.Failed("Should have failed to format invalid source.")
+	}
+	tests.Passed("Should have failed to format invalid source.")
+
+	ferr, ok := err.(*gen.FormatError)
+	if !ok || ferr.File != "mock.go" || ferr.Line != 5 {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have received FormatError with file name and line.")
+	}
+	tests.Passed("Should have received FormatError with file name and line.")
+
+	if err.Error() != "mock.go:5:1: expected operand, found '}'" {
+		tests.Info("Error: %+q", err.Error())
+		tests.Failed("Should have printed error with position.")
+	}
+	tests.Passed("Should have printed error with position.")
+}
+
+// TestFormatSourceWithUnknownImportNames validates imports whose name differs from their path are kept.
+func TestFormatSourceWithUnknownImportNames(t *testing.T) {
+	src := "package mock\n\nimport (\n\t\"os\"\n\n\tjsoniter \"github.com/json-iterator/go\"\n\t\"github.com/hashicorp/golang-lru\"\n\tunused \"github.com/influx6/faux/metrics\"\n)\n\nvar cache, _ = lru.New(10)\n\nvar json = jsoniter.ConfigFastest\n"
+	expected := "package mock\n\nimport (\n\t\"github.com/hashicorp/golang-lru\"\n\tjsoniter \"github.com/json-iterator/go\"\n)\n\nvar cache, _ = lru.New(10)\n\nvar json = jsoniter.ConfigFastest\n"
+
+	formatted, err := gen.FormatSource("mock.go", []byte(src))
+	if err != nil {
+		tests.Failed("Should have successfully formatted source: %+q.", err)
+	}
+	tests.Passed("Should have successfully formatted source.")
+
+	if string(formatted) != expected {
+		tests.Info("Source: %+q", string(formatted))
+		tests.Info("Expected: %+q", expected)
+		tests.Failed("Should have only removed imports with known names.")
+	}
+	tests.Passed("Should have only removed imports with known names.")
+}
+
+// TestFormatSourceWithImportComments validates comments within a import block are kept.
+func TestFormatSourceWithImportComments(t *testing.T) {
+	src := "package mock\n\nimport (\n\t// Standard library.\n\t\"fmt\"\n\t\"os\"\n\n\t// Metrics for mocks.\n\t\"github.com/influx6/faux/metrics\" // metrics\n)\n\nfunc Lower(m metrics.Metrics) string {\n\treturn fmt.Sprint(strings.ToLower(\"X\"))\n}\n"
+	expected := "package mock\n\nimport (\n\t// Standard library.\n\t\"fmt\"\n\t\"strings\"\n\n\t// Metrics for mocks.\n\t\"github.com/influx6/faux/metrics\" // metrics\n)\n\nfunc Lower(m metrics.Metrics) string {\n\treturn fmt.Sprint(strings.ToLower(\"X\"))\n}\n"
+
+	formatted, err := gen.FormatSource("mock.go", []byte(src))
+	if err != nil {
+		tests.Failed("Should have successfully formatted source: %+q.", err)
+	}
+	tests.Passed("Should have successfully formatted source.")
+
+	if string(formatted) != expected {
+		tests.Info("Source: %+q", string(formatted))
+		tests.Info("Expected: %+q", expected)
+		tests.Failed("Should have kept comments within import block.")
+	}
+	tests.Passed("Should have kept comments within import block.")
+}
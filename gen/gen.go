@@ -2,6 +2,7 @@ package gen
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -39,6 +40,7 @@ type WriteDirective struct {
 	Dir          string      `ast:"dir,optional"`      // Relative dir path written into it if not existing.
 	FileName     string      `ast:"filename,optional"` // alternative fileName to use for new file.
 	DontOverride bool        `ast:"dont_override,optional"`
+	Format       bool        `ast:"format,optional"` // Format .go files with gen.FormatSource before written.
 	Before       func() error
 	After        func() error
 }
@@ -1231,6 +1233,248 @@ func (v ValueAssignmentDeclr) WriteTo(w io.Writer) (int64, error) {
 
 //======================================================================================================================
 
+// SpecDeclr defines a declaration which produces a single const or var specification, e.g
+// `Name Type = Value`, where the type and value are optional.
+type SpecDeclr struct {
+	Name  NameDeclr   `json:"name"`
+	Type  TypeDeclr   `json:"type"`
+	Value io.WriterTo `json:"value"`
+}
+
+// WriteTo writes to the provided writer the specification declaration.
+func (s SpecDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("specDeclr", templates.Must("spec.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var value bytes.Buffer
+
+	if s.Value != nil {
+		if _, err := s.Value.WriteTo(&value); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Name  string
+		Type  string
+		Value string
+	}{
+		Name:  s.Name.String(),
+		Type:  s.Type.String(),
+		Value: value.String(),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// GroupDeclr defines a declaration which produces a const or var declaration for the giving
+// specifications, grouped within parenthesis if there is more than one.
+type GroupDeclr struct {
+	Keyword string      `json:"keyword"`
+	Specs   []SpecDeclr `json:"specs"`
+}
+
+// WriteTo writes to the provided writer the grouped declaration.
+func (g GroupDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("groupDeclr", templates.Must("group.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var specs []string
+
+	for _, spec := range g.Specs {
+		var item bytes.Buffer
+		if _, err := spec.WriteTo(&item); IsNotDrainError(err) {
+			return 0, err
+		}
+
+		specs = append(specs, item.String())
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Keyword string
+		Single  bool
+		Specs   []string
+	}{
+		Keyword: g.Keyword,
+		Single:  len(specs) == 1,
+		Specs:   specs,
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// IotaDeclr defines a declaration which produces a const block of typed values using iota.
+// The first value is assigned the Expression (which defaults to `iota`), where every iota
+// within it is offset by Offset if not zero. Names of `_` skip a value of the sequence.
+type IotaDeclr struct {
+	Type       TypeDeclr   `json:"type"`
+	Names      []NameDeclr `json:"names"`
+	Expression string      `json:"expression"`
+	Offset     int         `json:"offset"`
+}
+
+// Value returns the expression assigned to the first constant of the sequence.
+func (i IotaDeclr) Value() string {
+	if i.Expression == "" {
+		if i.Offset == 0 {
+			return "iota"
+		}
+
+		return fmt.Sprintf("iota + %d", i.Offset)
+	}
+
+	if i.Offset == 0 {
+		return i.Expression
+	}
+
+	return strings.Replace(i.Expression, "iota", fmt.Sprintf("(iota + %d)", i.Offset), -1)
+}
+
+// WriteTo writes to the provided writer the iota const declaration.
+func (i IotaDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	var specs []SpecDeclr
+
+	for index, name := range i.Names {
+		if index == 0 {
+			specs = append(specs, SpecDeclr{Name: name, Type: i.Type, Value: Text(i.Value())})
+			continue
+		}
+
+		specs = append(specs, SpecDeclr{Name: name})
+	}
+
+	// A single value is still grouped, as the sequence is of no use outside of a block.
+	if len(specs) == 1 {
+		specs = append(specs, SpecDeclr{Name: Name("_")})
+	}
+
+	return (GroupDeclr{Keyword: "const", Specs: specs}).WriteTo(w)
+}
+
+// EnumValueDeclr defines a value of a EnumDeclr with the name of it's constant and the text
+// it is represented as.
+type EnumValueDeclr struct {
+	Name NameDeclr `json:"name"`
+	Text string    `json:"text"`
+}
+
+// EnumDeclr defines a declaration which produces a enum type backed by a iota sequence of
+// constants, with a String() method, a Parse function and the encoding.TextMarshaler and
+// encoding.TextUnmarshaler methods.
+type EnumDeclr struct {
+	Name     NameDeclr        `json:"name"`
+	Type     TypeDeclr        `json:"type"`
+	Comments io.WriterTo      `json:"comments"`
+	Values   []EnumValueDeclr `json:"values"`
+	Offset   int              `json:"offset"`
+}
+
+// WriteTo writes to the provided writer the enum declaration, returning a error if the enum
+// has no name or no values.
+func (e EnumDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	name := e.Name.String()
+	if name == "" {
+		return 0, errors.New("enum declaration requires a name")
+	}
+
+	if len(e.Values) == 0 {
+		return 0, fmt.Errorf("enum declaration %q requires at least one value", name)
+	}
+
+	tml, err := ToTemplate("enumDeclr", templates.Must("enum.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	underline := e.Type.String()
+	if underline == "" {
+		underline = "int"
+	}
+
+	var comments, constants bytes.Buffer
+
+	if e.Comments != nil {
+		if _, err := e.Comments.WriteTo(&comments); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	type enumValue struct {
+		Const string
+		Text  string
+	}
+
+	var values []enumValue
+	var names []NameDeclr
+
+	for _, value := range e.Values {
+		names = append(names, value.Name)
+
+		if value.Name.String() == "_" {
+			continue
+		}
+
+		text := value.Text
+		if text == "" {
+			text = value.Name.String()
+		}
+
+		values = append(values, enumValue{Const: value.Name.String(), Text: text})
+	}
+
+	if _, err := (IotaDeclr{Type: Type(e.Name.String()), Names: names, Offset: e.Offset}).WriteTo(&constants); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	lower := strings.ToLower(name[:1]) + name[1:]
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Name      string
+		Type      string
+		Lower     string
+		Receiver  string
+		Comments  string
+		Constants string
+		Values    []enumValue
+	}{
+		Name:      name,
+		Type:      underline,
+		Lower:     lower,
+		Receiver:  lower[:1],
+		Comments:  comments.String(),
+		Constants: constants.String(),
+		Values:    values,
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+//======================================================================================================================
+
 // SuffixDeclr defines a declaration which produces a block with the provided prefix.
 type SuffixDeclr struct {
 	Suffix io.WriterTo `json:"-"`
@@ -1504,6 +1748,7 @@ func (c ConditionDeclr) WriteTo(w io.Writer) (int64, error) {
 // constructor and body.
 type FunctionDeclr struct {
 	Name        NameDeclr        `json:"name"`
+	TypeParams  TypeParamsDeclr  `json:"typeParams"`
 	Constructor ConstructorDeclr `json:"constructor"`
 	Returns     io.WriterTo      `json:"returns"`
 	Body        WritersTo        `json:"body"`
@@ -1513,7 +1758,11 @@ type FunctionDeclr struct {
 func (f FunctionDeclr) WriteTo(w io.Writer) (int64, error) {
 	w = NewNoBOM(w)
 
-	var constr, returns, body bytes.Buffer
+	var typeParams, constr, returns, body bytes.Buffer
+
+	if _, err := f.TypeParams.WriteTo(&typeParams); IsNotDrainError(err) {
+		return 0, err
+	}
 
 	if _, err := f.Constructor.WriteTo(&constr); IsNotDrainError(err) {
 		return 0, err
@@ -1529,11 +1778,13 @@ func (f FunctionDeclr) WriteTo(w io.Writer) (int64, error) {
 
 	var declr = struct {
 		Name        string
+		TypeParams  string
 		Returns     string
 		Body        string
 		Constructor string
 	}{
 		Name:        f.Name.String(),
+		TypeParams:  typeParams.String(),
 		Returns:     returns.String(),
 		Body:        body.String(),
 		Constructor: constr.String(),
@@ -1565,34 +1816,411 @@ type FunctionTypeDeclr struct {
 func (f FunctionTypeDeclr) WriteTo(w io.Writer) (int64, error) {
 	w = NewNoBOM(w)
 
-	var constr, returns bytes.Buffer
+	var constr, returns bytes.Buffer
+
+	if _, err := f.Constructor.WriteTo(&constr); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	if _, err := f.Returns.WriteTo(&returns); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	var declr = struct {
+		Name        string
+		Returns     string
+		Constructor string
+	}{
+		Name:        f.Name.String(),
+		Returns:     returns.String(),
+		Constructor: constr.String(),
+	}
+
+	tml, err := ToTemplate("functionTypeDeclr", templates.Must("function-type.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, declr); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+//======================================================================================================================
+
+// TypeParamDeclr defines a declaration which produces a type parameter of a generic type or
+// function with it's constraint, e.g `T any`.
+type TypeParamDeclr struct {
+	Name       NameDeclr `json:"name"`
+	Constraint TypeDeclr `json:"constraint"`
+}
+
+// WriteTo writes to the provided writer the type parameter declaration.
+func (t TypeParamDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	constraint := t.Constraint.String()
+	if constraint == "" {
+		constraint = "any"
+	}
+
+	return (ParamDeclr{Name: t.Name, Type: Type(constraint)}).WriteTo(w)
+}
+
+// TypeParamsDeclr defines a declaration which produces the type parameter list of a generic
+// type or function, e.g `[K comparable, V any]`. No output is produced if it has no parameters.
+type TypeParamsDeclr struct {
+	Params []TypeParamDeclr `json:"params"`
+}
+
+// WriteTo writes to the provided writer the type parameter list declaration.
+func (t TypeParamsDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	if len(t.Params) == 0 {
+		return 0, nil
+	}
+
+	var decals []io.WriterTo
+
+	for _, item := range t.Params {
+		decals = append(decals, item)
+	}
+
+	return (BlockDeclr{
+		Block:     CommaSpacedMapper.Map(decals...),
+		RuneBegin: '[',
+		RuneEnd:   ']',
+	}).WriteTo(w)
+}
+
+//======================================================================================================================
+
+// ReceiverDeclr defines a declaration which produces the receiver of a method, either
+// as a value `(r Type)` or a pointer `(r *Type)` receiver.
+type ReceiverDeclr struct {
+	Name    NameDeclr `json:"name"`
+	Type    TypeDeclr `json:"type"`
+	Pointer bool      `json:"pointer"`
+}
+
+// WriteTo writes to the provided writer the receiver declaration.
+func (r ReceiverDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("receiverDeclr", templates.Must("receiver.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Name    string
+		Type    string
+		Pointer bool
+	}{
+		Name:    r.Name.String(),
+		Type:    r.Type.String(),
+		Pointer: r.Pointer,
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// ParamDeclr defines a declaration which produces a single parameter or result of a
+// function signature, where the name may be empty for unnamed results.
+type ParamDeclr struct {
+	Name     NameDeclr `json:"name"`
+	Type     TypeDeclr `json:"type"`
+	Variadic bool      `json:"variadic"`
+}
+
+// WriteTo writes to the provided writer the parameter declaration.
+func (p ParamDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("paramDeclr", templates.Must("param.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Name     string
+		Type     string
+		Variadic bool
+	}{
+		Name:     p.Name.String(),
+		Type:     p.Type.String(),
+		Variadic: p.Variadic,
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// ParamsDeclr defines a declaration which produces the parameter list of a function
+// signature, e.g `(name string, values ...int)`.
+type ParamsDeclr struct {
+	Params []ParamDeclr `json:"params"`
+}
+
+// WriteTo writes to the provided writer the parameter list declaration.
+func (p ParamsDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	var decals []io.WriterTo
+
+	for _, item := range p.Params {
+		decals = append(decals, item)
+	}
+
+	return (BlockDeclr{
+		Block:     CommaSpacedMapper.Map(decals...),
+		RuneBegin: '(',
+		RuneEnd:   ')',
+	}).WriteTo(w)
+}
+
+// ResultsDeclr defines a declaration which produces the result list of a function
+// signature. No output is produced for no results and a single unnamed result is
+// written without parenthesis.
+type ResultsDeclr struct {
+	Results []ParamDeclr `json:"results"`
+}
+
+// WriteTo writes to the provided writer the result list declaration.
+func (r ResultsDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	if len(r.Results) == 0 {
+		return 0, nil
+	}
+
+	if len(r.Results) == 1 && r.Results[0].Name.Name == "" {
+		return r.Results[0].WriteTo(w)
+	}
+
+	return (ParamsDeclr{Params: r.Results}).WriteTo(w)
+}
+
+// MethodDeclr defines a declaration which produces a method for the giving receiver
+// based on the giving constructor, returns and body.
+type MethodDeclr struct {
+	Receiver    ReceiverDeclr `json:"receiver"`
+	Name        NameDeclr     `json:"name"`
+	Constructor io.WriterTo   `json:"constructor"`
+	Returns     io.WriterTo   `json:"returns"`
+	Body        WritersTo     `json:"body"`
+}
+
+// WriteTo writes to the provided writer the method declaration.
+func (m MethodDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	var receiver, constr, returns, body bytes.Buffer
+
+	if _, err := m.Receiver.WriteTo(&receiver); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	if m.Constructor != nil {
+		if _, err := m.Constructor.WriteTo(&constr); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if constr.Len() == 0 {
+		constr.WriteString("()")
+	}
+
+	if m.Returns != nil {
+		if _, err := m.Returns.WriteTo(&returns); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if _, err := m.Body.WriteTo(&body); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	var declr = struct {
+		Receiver    string
+		Name        string
+		Returns     string
+		Body        string
+		Constructor string
+	}{
+		Receiver:    receiver.String(),
+		Name:        m.Name.String(),
+		Returns:     returns.String(),
+		Body:        body.String(),
+		Constructor: constr.String(),
+	}
+
+	tml, err := ToTemplate("methodDeclr", templates.Must("method.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, declr); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+//======================================================================================================================
+
+// MethodSignatureDeclr defines a declaration which produces the signature of a method within
+// a interface, e.g `Read(data []byte) (int, error)`.
+type MethodSignatureDeclr struct {
+	Name        NameDeclr   `json:"name"`
+	Comments    io.WriterTo `json:"comments"`
+	Constructor io.WriterTo `json:"constructor"`
+	Returns     io.WriterTo `json:"returns"`
+}
+
+// WriteTo writes to the provided writer the method signature declaration.
+func (m MethodSignatureDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	var comments, constr, returns bytes.Buffer
+
+	if m.Comments != nil {
+		if _, err := m.Comments.WriteTo(&comments); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if m.Constructor != nil {
+		if _, err := m.Constructor.WriteTo(&constr); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if constr.Len() == 0 {
+		constr.WriteString("()")
+	}
+
+	if m.Returns != nil {
+		if _, err := m.Returns.WriteTo(&returns); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	tml, err := ToTemplate("methodSignatureDeclr", templates.Must("method-signature.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Name        string
+		Comments    string
+		Constructor string
+		Returns     string
+	}{
+		Name:        m.Name.String(),
+		Comments:    comments.String(),
+		Constructor: constr.String(),
+		Returns:     returns.String(),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// InterfaceDeclr defines a declaration which produces a go interface type with the giving
+// embedded interfaces and method signatures.
+type InterfaceDeclr struct {
+	Name        NameDeclr              `json:"name"`
+	TypeParams  TypeParamsDeclr        `json:"typeParams"`
+	Comments    io.WriterTo            `json:"comments"`
+	Annotations io.WriterTo            `json:"annotations"`
+	Embeds      []TypeDeclr            `json:"embeds"`
+	Methods     []MethodSignatureDeclr `json:"methods"`
+}
+
+// WriteTo writes to the provided writer the interface declaration.
+func (v InterfaceDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	var comments, annotations, typeParams bytes.Buffer
+
+	if v.Comments != nil {
+		if _, err := v.Comments.WriteTo(&comments); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if v.Annotations != nil {
+		if _, err := v.Annotations.WriteTo(&annotations); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if _, err := v.TypeParams.WriteTo(&typeParams); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	var members []string
 
-	if _, err := f.Constructor.WriteTo(&constr); IsNotDrainError(err) {
-		return 0, err
+	for _, embed := range v.Embeds {
+		members = append(members, "\t"+embed.String())
 	}
 
-	if _, err := f.Returns.WriteTo(&returns); IsNotDrainError(err) {
-		return 0, err
+	if len(v.Embeds) != 0 && len(v.Methods) != 0 {
+		members = append(members, "")
 	}
 
-	var declr = struct {
-		Name        string
-		Returns     string
-		Constructor string
-	}{
-		Name:        f.Name.String(),
-		Returns:     returns.String(),
-		Constructor: constr.String(),
+	for _, method := range v.Methods {
+		var signature bytes.Buffer
+		if _, err := method.WriteTo(&signature); IsNotDrainError(err) {
+			return 0, err
+		}
+
+		lines := strings.Split(strings.TrimSuffix(signature.String(), "\n"), "\n")
+		for index, line := range lines {
+			lines[index] = "\t" + line
+		}
+
+		members = append(members, lines...)
 	}
 
-	tml, err := ToTemplate("functionTypeDeclr", templates.Must("function-type.tml"), nil)
+	tml, err := ToTemplate("interfaceDeclr", templates.Must("interface.tml"), nil)
 	if err != nil {
 		return 0, err
 	}
 
 	wc := NewWriteCounter(w)
 
-	if err := tml.Execute(wc, declr); err != nil {
+	if err := tml.Execute(wc, struct {
+		Name        string
+		TypeParams  string
+		Comments    string
+		Annotations string
+		Members     []string
+	}{
+		Name:        v.Name.String(),
+		TypeParams:  typeParams.String(),
+		Comments:    comments.String(),
+		Annotations: annotations.String(),
+		Members:     members,
+	}); err != nil {
 		return 0, err
 	}
 
@@ -1666,11 +2294,12 @@ func (v StructTypeDeclr) WriteTo(w io.Writer) (int64, error) {
 
 // StructDeclr defines a declaration struct for representing a single comment.
 type StructDeclr struct {
-	Name        NameDeclr   `json:"name"`
-	Type        TypeDeclr   `json:"type"`
-	Comments    io.WriterTo `json:"comments"`
-	Annotations io.WriterTo `json:"annotations"`
-	Fields      WritersTo   `json:"fields"`
+	Name        NameDeclr       `json:"name"`
+	TypeParams  TypeParamsDeclr `json:"typeParams"`
+	Type        TypeDeclr       `json:"type"`
+	Comments    io.WriterTo     `json:"comments"`
+	Annotations io.WriterTo     `json:"annotations"`
+	Fields      WritersTo       `json:"fields"`
 }
 
 // WriteTo writes to the provided writer the variable declaration.
@@ -1693,6 +2322,12 @@ func (v StructDeclr) WriteTo(w io.Writer) (int64, error) {
 		return 0, err
 	}
 
+	var typeParams bytes.Buffer
+
+	if _, err := v.TypeParams.WriteTo(&typeParams); IsNotDrainError(err) {
+		return 0, err
+	}
+
 	var b bytes.Buffer
 	for _, item := range v.Fields {
 		b.Reset()
@@ -1707,6 +2342,7 @@ func (v StructDeclr) WriteTo(w io.Writer) (int64, error) {
 	wc := NewWriteCounter(w)
 	if err := tml.Execute(wc, struct {
 		Name        string
+		TypeParams  string
 		Type        string
 		Comments    string
 		Annotations string
@@ -1714,6 +2350,7 @@ func (v StructDeclr) WriteTo(w io.Writer) (int64, error) {
 	}{
 		Fields:      fields,
 		Name:        v.Name.String(),
+		TypeParams:  typeParams.String(),
 		Type:        v.Type.String(),
 		Comments:    comments.String(),
 		Annotations: annotations.String(),
@@ -2200,6 +2837,358 @@ func (c SwitchDeclr) WriteTo(w io.Writer) (int64, error) {
 
 //======================================================================================================================
 
+// ForDeclr defines a structure which generates a three-clause for loop declaration. A loop
+// without a init and post statement is generated as a condition only loop and a loop with
+// no clauses as a infinite loop.
+type ForDeclr struct {
+	Init      io.WriterTo
+	Condition io.WriterTo
+	Post      io.WriterTo
+	Action    io.WriterTo
+}
+
+// WriteTo writes to the provided writer the for loop declaration.
+func (f ForDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("forDeclr", templates.Must("for.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var init, condition, post, action bytes.Buffer
+
+	if f.Init != nil {
+		if _, err := f.Init.WriteTo(&init); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if f.Condition != nil {
+		if _, err := f.Condition.WriteTo(&condition); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if f.Post != nil {
+		if _, err := f.Post.WriteTo(&post); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if f.Action != nil {
+		if _, err := f.Action.WriteTo(&action); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	clause := strings.TrimSpace(condition.String())
+	if init.Len() != 0 || post.Len() != 0 {
+		clause = strings.Join(cleanSpace(init.String(), clause, post.String()), "; ")
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Clause string
+		Action string
+	}{
+		Clause: clause,
+		Action: strings.TrimSuffix(action.String(), "\n"),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// RangeDeclr defines a structure which generates a for loop declaration ranging over a
+// slice, map or channel. The key and value names are optional.
+type RangeDeclr struct {
+	Key    NameDeclr
+	Value  NameDeclr
+	Target io.WriterTo
+	Action io.WriterTo
+}
+
+// WriteTo writes to the provided writer the range loop declaration.
+func (r RangeDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("rangeDeclr", templates.Must("range.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var target, action bytes.Buffer
+
+	if _, err := r.Target.WriteTo(&target); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	if r.Action != nil {
+		if _, err := r.Action.WriteTo(&action); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	key := r.Key.String()
+	if key == "" && r.Value.String() != "" {
+		key = "_"
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Key    string
+		Value  string
+		Target string
+		Action string
+	}{
+		Key:    key,
+		Value:  r.Value.String(),
+		Target: target.String(),
+		Action: strings.TrimSuffix(action.String(), "\n"),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// LabelDeclr defines a structure which generates a labeled statement, used as the target of
+// a break or continue declaration.
+type LabelDeclr struct {
+	Label     NameDeclr
+	Statement io.WriterTo
+}
+
+// WriteTo writes to the provided writer the labeled statement declaration.
+func (l LabelDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("labelDeclr", templates.Must("label.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var statement bytes.Buffer
+
+	if _, err := l.Statement.WriteTo(&statement); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Label     string
+		Statement string
+	}{
+		Label:     l.Label.String(),
+		Statement: statement.String(),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// BranchDeclr defines a structure which generates a break or continue declaration with
+// a optional label.
+type BranchDeclr struct {
+	Branch string
+	Label  NameDeclr
+}
+
+// WriteTo writes to the provided writer the branch declaration.
+func (b BranchDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("branchDeclr", templates.Must("branch.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Branch string
+		Label  string
+	}{
+		Branch: b.Branch,
+		Label:  b.Label.String(),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// CallStatementDeclr defines a structure which generates a defer or go declaration for
+// the giving function call.
+type CallStatementDeclr struct {
+	Keyword string
+	Call    io.WriterTo
+}
+
+// WriteTo writes to the provided writer the call statement declaration.
+func (c CallStatementDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("callStatementDeclr", templates.Must("call-statement.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var call bytes.Buffer
+
+	if _, err := c.Call.WriteTo(&call); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Keyword string
+		Call    string
+	}{
+		Keyword: c.Keyword,
+		Call:    strings.TrimSpace(call.String()),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// ReturnValuesDeclr defines a structure which generates a return declaration with the
+// giving values.
+type ReturnValuesDeclr struct {
+	Values []io.WriterTo
+}
+
+// WriteTo writes to the provided writer the return declaration.
+func (r ReturnValuesDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("returnValuesDeclr", templates.Must("return.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var values bytes.Buffer
+
+	if _, err := CommaSpacedMapper.Map(r.Values...).WriteTo(&values); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Values string
+	}{
+		Values: values.String(),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+//======================================================================================================================
+
+// SelectCaseDeclr defines a structure which generates a select case declaration for a
+// send or receive operation on a channel.
+type SelectCaseDeclr struct {
+	Operation io.WriterTo
+	Behaviour io.WriterTo
+}
+
+// WriteTo writes to the provided writer the select case declaration.
+func (c SelectCaseDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("selectCaseDeclr", templates.Must("select-case.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var operation, action bytes.Buffer
+
+	if _, err := c.Operation.WriteTo(&operation); IsNotDrainError(err) {
+		return 0, err
+	}
+
+	if c.Behaviour != nil {
+		if _, err := c.Behaviour.WriteTo(&action); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Operation string
+		Action    string
+	}{
+		Operation: operation.String(),
+		Action:    strings.TrimSuffix(action.String(), "\n"),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+// SelectDeclr defines a structure which generates select declarations. The default case
+// is only generated if it has a behaviour.
+type SelectDeclr struct {
+	Cases   []SelectCaseDeclr
+	Default DefaultCaseDeclr
+}
+
+// WriteTo writes to the provided writer the select declaration.
+func (s SelectDeclr) WriteTo(w io.Writer) (int64, error) {
+	w = NewNoBOM(w)
+
+	tml, err := ToTemplate("selectDeclr", templates.Must("select.tml"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var cases, caseDefault bytes.Buffer
+
+	for _, item := range s.Cases {
+		if _, err := item.WriteTo(&cases); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	if s.Default.Behaviour != nil {
+		if _, err := s.Default.Behaviour.WriteTo(&caseDefault); IsNotDrainError(err) {
+			return 0, err
+		}
+	}
+
+	wc := NewWriteCounter(w)
+
+	if err := tml.Execute(wc, struct {
+		Cases      string
+		HasDefault bool
+		Default    string
+	}{
+		Cases:      cases.String(),
+		HasDefault: s.Default.Behaviour != nil,
+		Default:    strings.TrimSuffix(caseDefault.String(), "\n"),
+	}); err != nil {
+		return 0, err
+	}
+
+	return wc.Written(), nil
+}
+
+//======================================================================================================================
+
 // NoBOMWriter removes any unwanted characters like \x00 found in
 // possible code.
 type NoBOMWriter struct {
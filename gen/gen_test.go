@@ -2,6 +2,7 @@ package gen_test
 
 import (
 	"bytes"
+	"go/format"
 	"io"
 	"testing"
 
@@ -74,3 +75,289 @@ func TestMapGen(t *testing.T) {
 	}
 	tests.Passed("Should have successfully matched generated output with expected.")
 }
+
+// TestMethodGen validates the generation of methods with value and pointer receivers.
+func TestMethodGen(t *testing.T) {
+	expected := "\nfunc (f *Floppy) Read(data []byte, names ...string) (n int, err error) {\nreturn 0, nil\n}\n\nfunc (Floppy) Close() {\n\n}\n\nfunc (f Floppy) Name() string {\nreturn f.name\n}\n"
+
+	src := gen.Block(
+		gen.Method(
+			gen.PointerReceiver(gen.Name("f"), gen.Type("Floppy")),
+			gen.Name("Read"),
+			gen.Params(
+				gen.Param(gen.Name("data"), gen.Type("[]byte")),
+				gen.VariadicParam(gen.Name("names"), gen.Type("string")),
+			),
+			gen.Results(
+				gen.Param(gen.Name("n"), gen.Type("int")),
+				gen.Param(gen.Name("err"), gen.Type("error")),
+			),
+			gen.Text("return 0, nil"),
+		),
+		gen.Method(
+			gen.Receiver(gen.Name(""), gen.Type("Floppy")),
+			gen.Name("Close"),
+			nil,
+			nil,
+		),
+		gen.Method(
+			gen.Receiver(gen.Name("f"), gen.Type("Floppy")),
+			gen.Name("Name"),
+			gen.Params(),
+			gen.Results(gen.Param(gen.Name(""), gen.Type("string"))),
+			gen.Text("return f.name"),
+		),
+	)
+
+	var bu bytes.Buffer
+
+	if _, err := src.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written source output: %+q.", err)
+	}
+	tests.Passed("Should have successfully written source output.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+
+		tests.Failed("Should have successfully matched generated output with expected.")
+	}
+	tests.Passed("Should have successfully matched generated output with expected.")
+}
+
+// TestInterfaceGen validates the generation of a interface with embedded interfaces and methods.
+func TestInterfaceGen(t *testing.T) {
+	expected := "// Store defines a store.\n//@mock\ntype Store interface {\n\tio.Closer\n\n\t// Get returns the value of key.\n\tGet(key string) (string, error)\n\tKeys(prefixes ...string) []string\n\tReset()\n}\n"
+
+	src := gen.InterfaceType(
+		gen.Name("Store"),
+		gen.Doc("Store defines a store."),
+		gen.Annotation("mock"),
+		[]gen.TypeDeclr{gen.Type("io.Closer")},
+		gen.MethodSignature(
+			gen.Name("Get"),
+			gen.Doc("Get returns the value of key."),
+			gen.Params(gen.Param(gen.Name("key"), gen.Type("string"))),
+			gen.Results(gen.Param(gen.Name(""), gen.Type("string")), gen.Param(gen.Name(""), gen.Type("error"))),
+		),
+		gen.MethodSignature(
+			gen.Name("Keys"),
+			nil,
+			gen.Params(gen.VariadicParam(gen.Name("prefixes"), gen.Type("string"))),
+			gen.Results(gen.Param(gen.Name(""), gen.Type("[]string"))),
+		),
+		gen.MethodSignature(gen.Name("Reset"), nil, nil, nil),
+	)
+
+	var bu bytes.Buffer
+
+	if _, err := src.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written source output: %+q.", err)
+	}
+	tests.Passed("Should have successfully written source output.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+
+		tests.Failed("Should have successfully matched generated output with expected.")
+	}
+	tests.Passed("Should have successfully matched generated output with expected.")
+}
+
+// TestStatementsGen validates the generation of loop, branch, defer, go, select and return statements.
+func TestStatementsGen(t *testing.T) {
+	expected := "defer wg.Done()\ngo worker(jobs)\nouter:\nfor i := 0; i < 10; i++ {\nfor _, job := range jobs {\nselect {\ncase results <- job:\ncontinue outer\ncase err, ok := <-errs:\nbreak outer\ndefault:\nbreak\n}\n}\n}\n\nfor {\nreturn\n}\nfor key := range keys {\nreturn key, nil\n}\n"
+
+	src := gen.Block(
+		gen.Defer(gen.Text("wg.Done()")),
+		gen.Go(gen.Text("worker(jobs)")),
+		gen.Label(
+			gen.Name("outer"),
+			gen.For(
+				gen.Text("i := 0"),
+				gen.Text("i < 10"),
+				gen.Text("i++"),
+				gen.Range(
+					gen.Name(""),
+					gen.Name("job"),
+					gen.Text("jobs"),
+					gen.Select(
+						gen.DefaultCase(gen.Break()),
+						gen.SendCase(gen.Text("results"), gen.Text("job"), gen.ContinueTo(gen.Name("outer"))),
+						gen.ReceiveCase(gen.Text("errs"), gen.BreakTo(gen.Name("outer")), gen.Name("err"), gen.Name("ok")),
+					),
+				),
+			),
+		),
+		gen.For(nil, nil, nil, gen.Return()),
+		gen.Range(gen.Name("key"), gen.Name(""), gen.Text("keys"), gen.Return(gen.Text("key"), gen.Text("nil"))),
+	)
+
+	var bu bytes.Buffer
+
+	if _, err := src.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written source output: %+q.", err)
+	}
+	tests.Passed("Should have successfully written source output.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+
+		tests.Failed("Should have successfully matched generated output with expected.")
+	}
+	tests.Passed("Should have successfully matched generated output with expected.")
+
+	function := "package mock\n\nfunc mock() {\n" + bu.String() + "}\n"
+	if _, err := format.Source([]byte(function)); err != nil {
+		tests.Info("Source: %s", function)
+		tests.Failed("Should have generated valid Go statements: %+q.", err)
+	}
+	tests.Passed("Should have generated valid Go statements.")
+}
+
+// TestConstsGen validates the generation of grouped const, var and iota declarations.
+func TestConstsGen(t *testing.T) {
+	expected := "const Version = \"1.0\"\nvar (\n\tErrMock = errors.New(\"mock\")\n\tcount int\n)\nconst (\n\t_ Flag = 1 << (iota + 1)\n\tFlagRead\n\tFlagWrite\n)\n"
+
+	src := gen.Block(
+		gen.Consts(gen.Spec(gen.Name("Version"), gen.Type(""), gen.Text(`"1.0"`))),
+		gen.Vars(
+			gen.Spec(gen.Name("ErrMock"), gen.Type(""), gen.Text(`errors.New("mock")`)),
+			gen.Spec(gen.Name("count"), gen.Type("int"), nil),
+		),
+		gen.IotaWith(gen.Type("Flag"), "1 << iota", 1, gen.Name("_"), gen.Name("FlagRead"), gen.Name("FlagWrite")),
+	)
+
+	var bu bytes.Buffer
+
+	if _, err := src.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written source output: %+q.", err)
+	}
+	tests.Passed("Should have successfully written source output.")
+
+	if bu.String() != expected {
+		tests.Info("Source: %+q", bu.String())
+		tests.Info("Expected: %+q", expected)
+
+		tests.Failed("Should have successfully matched generated output with expected.")
+	}
+	tests.Passed("Should have successfully matched generated output with expected.")
+}
+
+// TestEnumGen validates the generation of a enum type with it's methods.
+func TestEnumGen(t *testing.T) {
+	src := gen.Package(
+		gen.Name("mock"),
+		gen.Imports(gen.Import("fmt", "")),
+		gen.Enum(
+			gen.Name("Color"),
+			gen.Type("uint8"),
+			gen.Doc("Color defines the color of a item."),
+			gen.EnumValue(gen.Name("ColorUnknown"), "unknown"),
+			gen.EnumValue(gen.Name("ColorRed"), "red"),
+			gen.EnumValue(gen.Name("_"), ""),
+			gen.EnumValue(gen.Name("ColorBlue"), ""),
+		),
+	)
+
+	var bu bytes.Buffer
+
+	if _, err := src.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written source output: %+q.", err)
+	}
+	tests.Passed("Should have successfully written source output.")
+
+	formatted, err := gen.FormatSource("color.go", bu.Bytes())
+	if err != nil {
+		tests.Info("Source: %s", bu.String())
+		tests.Failed("Should have generated valid go source: %+q.", err)
+	}
+	tests.Passed("Should have generated valid go source.")
+
+	for _, expected := range []string{
+		"type Color uint8\n",
+		"\tColorUnknown Color = iota\n\tColorRed\n\t_\n\tColorBlue\n",
+		"\tColorBlue:    \"ColorBlue\",\n",
+		"\t\"red\":       ColorRed,\n",
+		"func (c Color) String() string {",
+		"func ParseColor(name string) (Color, error) {",
+		"func (c Color) MarshalText() ([]byte, error) {",
+		"func (c *Color) UnmarshalText(text []byte) error {",
+	} {
+		if !bytes.Contains(formatted, []byte(expected)) {
+			tests.Info("Source: %s", formatted)
+			tests.Failed("Should have generated %+q.", expected)
+		}
+	}
+	tests.Passed("Should have generated enum type, constants and methods.")
+
+	if _, err := gen.Enum(gen.Name(""), gen.Type(""), nil, gen.EnumValue(gen.Name("Unknown"), "")).WriteTo(&bu); err == nil {
+		tests.Failed("Should have failed to write enum without name.")
+	}
+	tests.Passed("Should have failed to write enum without name.")
+
+	if _, err := gen.Enum(gen.Name("Empty"), gen.Type("int"), nil).WriteTo(&bu); err == nil {
+		tests.Failed("Should have failed to write enum without values.")
+	}
+	tests.Passed("Should have failed to write enum without values.")
+}
+
+// TestGenericsGen validates the generation of type parameters for structs, functions and interfaces.
+func TestGenericsGen(t *testing.T) {
+	expected := "type Pair[K comparable, V any] struct {\n\tKey K\n\n\tValue V\n}\n\nfunc Zero[K comparable, V any]() Pair[K, V] {\n\tvar p Pair[K, V]\n\treturn p\n}\n\ntype Getter[T any] interface {\n\tGet() T\n}\n"
+
+	params := gen.TypeParams(
+		gen.TypeParam(gen.Name("K"), gen.Type("comparable")),
+		gen.TypeParam(gen.Name("V"), gen.Type("")),
+	)
+
+	src := gen.Package(
+		gen.Name("mock"),
+		gen.GenericStruct(
+			gen.Name("Pair"),
+			params,
+			nil,
+			nil,
+			gen.FieldType(gen.Name("Key"), gen.Type("K")),
+			gen.FieldType(gen.Name("Value"), gen.Type("V")),
+		),
+		gen.GenericFunction(
+			gen.Name("Zero"),
+			params,
+			gen.Constructor(),
+			gen.Instance("Pair", gen.Type("K"), gen.Type("V")),
+			gen.Text("var p Pair[K, V]\nreturn p"),
+		),
+		gen.InterfaceDeclr{
+			Name:       gen.Name("Getter"),
+			TypeParams: gen.TypeParams(gen.TypeParam(gen.Name("T"), gen.Type("any"))),
+			Methods: []gen.MethodSignatureDeclr{
+				gen.MethodSignature(gen.Name("Get"), nil, nil, gen.Results(gen.Param(gen.Name(""), gen.Type("T")))),
+			},
+		},
+	)
+
+	var bu bytes.Buffer
+
+	if _, err := src.WriteTo(&bu); err != nil && err != io.EOF {
+		tests.Failed("Should have successfully written source output: %+q.", err)
+	}
+	tests.Passed("Should have successfully written source output.")
+
+	formatted, err := gen.FormatSource("mock.go", bu.Bytes())
+	if err != nil {
+		tests.Info("Source: %s", bu.String())
+		tests.Failed("Should have generated valid go source: %+q.", err)
+	}
+	tests.Passed("Should have generated valid go source.")
+
+	if !bytes.HasSuffix(formatted, []byte(expected)) {
+		tests.Info("Source: %+q", string(formatted))
+		tests.Info("Expected: %+q", expected)
+
+		tests.Failed("Should have successfully matched generated output with expected.")
+	}
+	tests.Passed("Should have successfully matched generated output with expected.")
+}
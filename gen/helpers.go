@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 )
 
@@ -292,6 +293,44 @@ func Interface(name NameDeclr, comments io.WriterTo, annotations io.WriterTo, fi
 	}
 }
 
+// InterfaceType returns a new instance of a InterfaceDeclr to generate a go interface with
+// the giving embedded interfaces and method signatures.
+func InterfaceType(name NameDeclr, comments io.WriterTo, annotations io.WriterTo, embeds []TypeDeclr, methods ...MethodSignatureDeclr) InterfaceDeclr {
+	return InterfaceDeclr{
+		Name:        name,
+		Comments:    comments,
+		Annotations: annotations,
+		Embeds:      embeds,
+		Methods:     methods,
+	}
+}
+
+// MethodSignature returns a new instance of a MethodSignatureDeclr.
+func MethodSignature(name NameDeclr, comments io.WriterTo, constr io.WriterTo, returns io.WriterTo) MethodSignatureDeclr {
+	return MethodSignatureDeclr{
+		Name:        name,
+		Comments:    comments,
+		Constructor: constr,
+		Returns:     returns,
+	}
+}
+
+// Doc returns a io.WriterTo which writes the giving text as line comments, e.g the text of a
+// parsed declaration's documentation.
+func Doc(text string) io.WriterTo {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Text("")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, strings.TrimSpace("// "+line)+"\n")
+	}
+
+	return Text(strings.Join(lines, ""))
+}
+
 // Struct returns a new instance of a StructDeclr to generate a go struct.
 func Struct(name NameDeclr, comments io.WriterTo, annotations io.WriterTo, fields ...io.WriterTo) StructDeclr {
 	if annotations == nil {
@@ -365,6 +404,65 @@ func Function(name NameDeclr, constr ConstructorDeclr, returns io.WriterTo, body
 	}
 }
 
+// Method returns a new instance of a MethodDeclr for the giving receiver.
+func Method(receiver ReceiverDeclr, name NameDeclr, constr io.WriterTo, returns io.WriterTo, body ...io.WriterTo) MethodDeclr {
+	return MethodDeclr{
+		Receiver:    receiver,
+		Name:        name,
+		Constructor: constr,
+		Returns:     returns,
+		Body:        body,
+	}
+}
+
+// Receiver returns a new instance of a ReceiverDeclr for a value receiver.
+func Receiver(name NameDeclr, ntype TypeDeclr) ReceiverDeclr {
+	return ReceiverDeclr{
+		Name: name,
+		Type: ntype,
+	}
+}
+
+// PointerReceiver returns a new instance of a ReceiverDeclr for a pointer receiver.
+func PointerReceiver(name NameDeclr, ntype TypeDeclr) ReceiverDeclr {
+	return ReceiverDeclr{
+		Name:    name,
+		Type:    ntype,
+		Pointer: true,
+	}
+}
+
+// Param returns a new instance of a ParamDeclr.
+func Param(name NameDeclr, ntype TypeDeclr) ParamDeclr {
+	return ParamDeclr{
+		Name: name,
+		Type: ntype,
+	}
+}
+
+// VariadicParam returns a new instance of a ParamDeclr for a variadic parameter.
+func VariadicParam(name NameDeclr, ntype TypeDeclr) ParamDeclr {
+	return ParamDeclr{
+		Name:     name,
+		Type:     ntype,
+		Variadic: true,
+	}
+}
+
+// Params returns a new instance of a ParamsDeclr.
+func Params(params ...ParamDeclr) ParamsDeclr {
+	return ParamsDeclr{
+		Params: params,
+	}
+}
+
+// Results returns a new instance of a ResultsDeclr.
+func Results(results ...ParamDeclr) ResultsDeclr {
+	return ResultsDeclr{
+		Results: results,
+	}
+}
+
 // SourceWith returns a new instance of a SourceDeclr.
 func SourceWith(tml *template.Template, dfns template.FuncMap, binding interface{}) SourceDeclr {
 	return SourceDeclr{
@@ -603,3 +701,233 @@ func If(condition, action io.WriterTo) IfDeclr {
 		Condition: condition,
 	}
 }
+
+// For returns a new instance of a ForDeclr. The init, condition and post statements may be
+// nil, where a loop with only a condition produces `for condition {` and a loop with none
+// produces `for {`.
+func For(init, condition, post, action io.WriterTo) ForDeclr {
+	return ForDeclr{
+		Init:      init,
+		Condition: condition,
+		Post:      post,
+		Action:    action,
+	}
+}
+
+// Range returns a new instance of a RangeDeclr ranging over the target with the giving key
+// and value names, either of which can be empty.
+func Range(key, value NameDeclr, target, action io.WriterTo) RangeDeclr {
+	return RangeDeclr{
+		Key:    key,
+		Value:  value,
+		Target: target,
+		Action: action,
+	}
+}
+
+// Label returns a new instance of a LabelDeclr.
+func Label(label NameDeclr, statement io.WriterTo) LabelDeclr {
+	return LabelDeclr{
+		Label:     label,
+		Statement: statement,
+	}
+}
+
+// Break returns a new instance of a BranchDeclr for a break statement.
+func Break() BranchDeclr {
+	return BranchDeclr{
+		Branch: "break",
+	}
+}
+
+// BreakTo returns a new instance of a BranchDeclr for a break statement to the giving label.
+func BreakTo(label NameDeclr) BranchDeclr {
+	return BranchDeclr{
+		Branch: "break",
+		Label:  label,
+	}
+}
+
+// Continue returns a new instance of a BranchDeclr for a continue statement.
+func Continue() BranchDeclr {
+	return BranchDeclr{
+		Branch: "continue",
+	}
+}
+
+// ContinueTo returns a new instance of a BranchDeclr for a continue statement to the giving label.
+func ContinueTo(label NameDeclr) BranchDeclr {
+	return BranchDeclr{
+		Branch: "continue",
+		Label:  label,
+	}
+}
+
+// Defer returns a new instance of a CallStatementDeclr for a defer statement.
+func Defer(call io.WriterTo) CallStatementDeclr {
+	return CallStatementDeclr{
+		Keyword: "defer",
+		Call:    call,
+	}
+}
+
+// Go returns a new instance of a CallStatementDeclr for a go statement.
+func Go(call io.WriterTo) CallStatementDeclr {
+	return CallStatementDeclr{
+		Keyword: "go",
+		Call:    call,
+	}
+}
+
+// Return returns a new instance of a ReturnValuesDeclr.
+func Return(values ...io.WriterTo) ReturnValuesDeclr {
+	return ReturnValuesDeclr{
+		Values: values,
+	}
+}
+
+// Select returns a new instance of a SelectDeclr. The default case is left out if it has
+// no behaviour.
+func Select(def DefaultCaseDeclr, cases ...SelectCaseDeclr) SelectDeclr {
+	return SelectDeclr{
+		Default: def,
+		Cases:   cases,
+	}
+}
+
+// SendCase returns a new instance of a SelectCaseDeclr which sends the value on the channel.
+func SendCase(channel, value, action io.WriterTo) SelectCaseDeclr {
+	return SelectCaseDeclr{
+		Operation: WritersTo{channel, Text(" <- "), value},
+		Behaviour: action,
+	}
+}
+
+// ReceiveCase returns a new instance of a SelectCaseDeclr which receives from the channel,
+// assigning the received value and ok flag to the giving names if provided.
+func ReceiveCase(channel, action io.WriterTo, names ...NameDeclr) SelectCaseDeclr {
+	var assigned []string
+	for _, name := range names {
+		assigned = append(assigned, name.String())
+	}
+
+	var receive WritersTo
+	if len(assigned) != 0 {
+		receive = append(receive, Text(strings.Join(assigned, ", ")+" := "))
+	}
+
+	return SelectCaseDeclr{
+		Operation: append(receive, Text("<-"), channel),
+		Behaviour: action,
+	}
+}
+
+// Spec returns a new instance of a SpecDeclr for a const or var declaration, where the
+// type and value can be left empty.
+func Spec(name NameDeclr, ntype TypeDeclr, value io.WriterTo) SpecDeclr {
+	return SpecDeclr{
+		Name:  name,
+		Type:  ntype,
+		Value: value,
+	}
+}
+
+// Consts returns a new instance of a GroupDeclr for a const declaration.
+func Consts(specs ...SpecDeclr) GroupDeclr {
+	return GroupDeclr{
+		Keyword: "const",
+		Specs:   specs,
+	}
+}
+
+// Vars returns a new instance of a GroupDeclr for a var declaration.
+func Vars(specs ...SpecDeclr) GroupDeclr {
+	return GroupDeclr{
+		Keyword: "var",
+		Specs:   specs,
+	}
+}
+
+// Iota returns a new instance of a IotaDeclr for a sequence of constants of the giving type.
+func Iota(ntype TypeDeclr, names ...NameDeclr) IotaDeclr {
+	return IotaDeclr{
+		Type:  ntype,
+		Names: names,
+	}
+}
+
+// IotaWith returns a new instance of a IotaDeclr for a sequence of constants of the giving type,
+// using the expression (e.g `1 << iota`) and offset for the first constant.
+func IotaWith(ntype TypeDeclr, expression string, offset int, names ...NameDeclr) IotaDeclr {
+	return IotaDeclr{
+		Type:       ntype,
+		Names:      names,
+		Expression: expression,
+		Offset:     offset,
+	}
+}
+
+// Enum returns a new instance of a EnumDeclr of the giving name and underline type.
+func Enum(name NameDeclr, ntype TypeDeclr, comments io.WriterTo, values ...EnumValueDeclr) EnumDeclr {
+	return EnumDeclr{
+		Name:     name,
+		Type:     ntype,
+		Comments: comments,
+		Values:   values,
+	}
+}
+
+// EnumValue returns a new instance of a EnumValueDeclr, where the constant is represented
+// by the giving text or by it's name if empty.
+func EnumValue(name NameDeclr, text string) EnumValueDeclr {
+	return EnumValueDeclr{
+		Name: name,
+		Text: text,
+	}
+}
+
+// TypeParam returns a new instance of a TypeParamDeclr, where a empty constraint is
+// written as `any`.
+func TypeParam(name NameDeclr, constraint TypeDeclr) TypeParamDeclr {
+	return TypeParamDeclr{
+		Name:       name,
+		Constraint: constraint,
+	}
+}
+
+// TypeParams returns a new instance of a TypeParamsDeclr.
+func TypeParams(params ...TypeParamDeclr) TypeParamsDeclr {
+	return TypeParamsDeclr{
+		Params: params,
+	}
+}
+
+// Instance returns a new instance of a TypeDeclr referencing the generic type instantiated
+// with the giving type arguments, e.g `List[T]` or `Map[string, int]`.
+func Instance(name string, args ...TypeDeclr) TypeDeclr {
+	if len(args) == 0 {
+		return Type(name)
+	}
+
+	var types []string
+	for _, arg := range args {
+		types = append(types, arg.String())
+	}
+
+	return Type(fmt.Sprintf("%s[%s]", name, strings.Join(types, ", ")))
+}
+
+// GenericStruct returns a new instance of a StructDeclr to generate a go struct with the giving
+// type parameters.
+func GenericStruct(name NameDeclr, typeParams TypeParamsDeclr, comments io.WriterTo, annotations io.WriterTo, fields ...io.WriterTo) StructDeclr {
+	declr := Struct(name, comments, annotations, fields...)
+	declr.TypeParams = typeParams
+	return declr
+}
+
+// GenericFunction returns a new instance of a FunctionDeclr with the giving type parameters.
+func GenericFunction(name NameDeclr, typeParams TypeParamsDeclr, constr ConstructorDeclr, returns io.WriterTo, body ...io.WriterTo) FunctionDeclr {
+	declr := Function(name, constr, returns, body...)
+	declr.TypeParams = typeParams
+	return declr
+}
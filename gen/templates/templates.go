@@ -40,7 +40,7 @@ func init() {
 	internalFiles["comments.tml"] = "// {{.MainBlock}}\n// {{ range .Blocks}}\n// {{.}}\n// {{end}}\n//\n"
 	internalFiles["function-type.tml"] = "func {{.Name}}{{.Constructor}} {{.Returns}}"
 	internalFiles["jsonblock.tml"] = "{\n{{ $len := subtract (len .) 1 }}\n{{ range $ind, $item := . }}\n    {{ if lessThan $ind $len}}{{$item}},{{else}}{{$item}}{{end}}\n{{ end }}\n}"
-	internalFiles["struct.tml"] = "{{.Comments}}\n{{.Annotations}}\ntype {{.Name}} {{.Type}} {\n{{ range .Fields }}\n    {{.}} \n{{ end }}\n}"
+	internalFiles["struct.tml"] = "{{.Comments}}\n{{.Annotations}}\ntype {{.Name}}{{.TypeParams}} {{.Type}} {\n{{ range .Fields }}\n    {{.}} \n{{ end }}\n}"
 	internalFiles["value.tml"] = "{{.Value}}"
 	internalFiles["variable-assign.tml"] = "{{.Name}}:={{.Value}}\n"
 	internalFiles["map.tml"] = "{{.MapType}}[{{.Type}}]{{.Value}}{\n    {{ range $k, $v :=  .Values }}\n        {{quote $k}}: {{$v}},\n    {{ end }}\n}"
@@ -48,7 +48,7 @@ func init() {
 	internalFiles["structtype.tml"] = "{{.Name}} {{.Type}} {{.Tags}}"
 	internalFiles["variable-type.tml"] = "{{.Name}} {{.Type}}\n"
 	internalFiles["array.tml"] = "[{{.Size}}]{{.Type}}"
-	internalFiles["function.tml"] = "\nfunc {{.Name}}{{.Constructor}} {{.Returns}} {\n{{.Body}}\n}\n"
+	internalFiles["function.tml"] = "\nfunc {{.Name}}{{.TypeParams}}{{.Constructor}} {{.Returns}} {\n{{.Body}}\n}\n"
 	internalFiles["if.tml"] = "if{{.Condition}}{\n{{.Action}}\n}"
 	internalFiles["annotations.tml"] = "//@{{.Value}}"
 	internalFiles["case-default.tml"] = "default:\n    {{.Action}}\n\n\n "
@@ -62,5 +62,21 @@ func init() {
 	internalFiles["slicetype.tml"] = "[]{{.Type}}"
 	internalFiles["switch.tml"] = "switch {{.Condition}} {\n{{.Case }}\n{{.Default }}\n}"
 	internalFiles["text.tml"] = "{{.Block}}"
+	internalFiles["method.tml"] = "\nfunc {{.Receiver}} {{.Name}}{{.Constructor}} {{if .Returns}}{{.Returns}} {{end}}{\n{{.Body}}\n}\n"
+	internalFiles["receiver.tml"] = "({{if .Name}}{{.Name}} {{end}}{{if .Pointer}}*{{end}}{{.Type}})"
+	internalFiles["param.tml"] = "{{if .Name}}{{.Name}} {{end}}{{if .Variadic}}...{{end}}{{.Type}}"
+	internalFiles["method-signature.tml"] = "{{.Comments}}{{.Name}}{{.Constructor}}{{if .Returns}} {{.Returns}}{{end}}"
+	internalFiles["interface.tml"] = "{{.Comments}}{{if .Annotations}}{{.Annotations}}\n{{end}}type {{.Name}}{{.TypeParams}} interface {\n{{ range .Members }}{{.}}\n{{ end }}}\n"
+	internalFiles["for.tml"] = "for {{if .Clause}}{{.Clause}} {{end}}{\n{{.Action}}\n}\n"
+	internalFiles["range.tml"] = "for {{if .Key}}{{.Key}}{{if .Value}}, {{.Value}}{{end}} := {{end}}range {{.Target}} {\n{{.Action}}\n}\n"
+	internalFiles["label.tml"] = "{{.Label}}:\n{{.Statement}}\n"
+	internalFiles["branch.tml"] = "{{.Branch}}{{if .Label}} {{.Label}}{{end}}\n"
+	internalFiles["call-statement.tml"] = "{{.Keyword}} {{.Call}}\n"
+	internalFiles["return.tml"] = "return{{if .Values}} {{.Values}}{{end}}\n"
+	internalFiles["select-case.tml"] = "case {{.Operation}}:\n{{.Action}}\n"
+	internalFiles["select.tml"] = "select {\n{{.Cases}}{{if .HasDefault}}default:\n{{.Default}}\n{{end}}}\n"
+	internalFiles["spec.tml"] = "{{.Name}}{{if .Type}} {{.Type}}{{end}}{{if .Value}} = {{.Value}}{{end}}"
+	internalFiles["group.tml"] = "{{.Keyword}} {{if .Single}}{{index .Specs 0}}\n{{else}}(\n{{ range .Specs }}\t{{.}}\n{{ end }})\n{{end}}"
+	internalFiles["enum.tml"] = "{{.Comments}}type {{.Name}} {{.Type}}\n\n{{.Constants}}\nvar {{.Lower}}Names = map[{{.Name}}]string{\n{{ range .Values }}\t{{.Const}}: {{quote .Text}},\n{{ end }}}\n\nvar {{.Lower}}Values = map[string]{{.Name}}{\n{{ range .Values }}\t{{quote .Text}}: {{.Const}},\n{{ end }}}\n\n// String returns the name of the {{.Name}}.\nfunc ({{.Receiver}} {{.Name}}) String() string {\n\tif name, ok := {{.Lower}}Names[{{.Receiver}}]; ok {\n\t\treturn name\n\t}\n\n\treturn fmt.Sprintf(\"{{.Name}}(%v)\", {{.Type}}({{.Receiver}}))\n}\n\n// Parse{{.Name}} returns the {{.Name}} of the giving name.\nfunc Parse{{.Name}}(name string) ({{.Name}}, error) {\n\tif value, ok := {{.Lower}}Values[name]; ok {\n\t\treturn value, nil\n\t}\n\n\treturn 0, fmt.Errorf(\"invalid {{.Name}} %q\", name)\n}\n\n// MarshalText implements the encoding.TextMarshaler interface.\nfunc ({{.Receiver}} {{.Name}}) MarshalText() ([]byte, error) {\n\tif name, ok := {{.Lower}}Names[{{.Receiver}}]; ok {\n\t\treturn []byte(name), nil\n\t}\n\n\treturn nil, fmt.Errorf(\"invalid {{.Name}} %v\", {{.Type}}({{.Receiver}}))\n}\n\n// UnmarshalText implements the encoding.TextUnmarshaler interface.\nfunc ({{.Receiver}} *{{.Name}}) UnmarshalText(text []byte) error {\n\tvalue, err := Parse{{.Name}}(string(text))\n\tif err != nil {\n\t\treturn err\n\t}\n\n\t*{{.Receiver}} = value\n\treturn nil\n}\n"
 
 }
@@ -0,0 +1,336 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+// ConfigFile defines the default name of the plugin config file.
+const ConfigFile = "moz.json"
+
+// ErrNoConfig defines the error returned when no config file could be found.
+var ErrNoConfig = errors.New("No moz plugin config file found")
+
+// Config defines the content of a plugin config file, which lists the plugins to be
+// registered by their annotation name.
+//
+//	{
+//	  "plugins": [
+//	    {"name": "mongo", "command": "moz-mongo", "kinds": ["struct"]},
+//	    {"name": "iface", "command": "./bin/moz-iface", "args": ["-mocks"], "timeout": "1m"}
+//	  ]
+//	}
+type Config struct {
+	Dir     string   `json:"-"`
+	Plugins []Plugin `json:"plugins"`
+}
+
+// Plugin defines a executable which generates the directives for annotations with
+// the plugin's name. Kinds limits the declarations the plugin is registered for,
+// registering for all kinds if empty.
+type Plugin struct {
+	Name      string   `json:"name"`
+	Command   string   `json:"command"`
+	Args      []string `json:"args"`
+	Kinds     []string `json:"kinds"`
+	Timeout   string   `json:"timeout"`
+	Dir       string   `json:"-"`
+	BuildTags []string `json:"-"`
+}
+
+// FindConfig returns the Config found within the giving directory or it's nearest parent.
+// It returns ErrNoConfig if no config file exists.
+func FindConfig(dir string) (Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Config{}, err
+	}
+
+	for {
+		path := filepath.Join(dir, ConfigFile)
+		if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+			return LoadConfig(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Config{}, ErrNoConfig
+		}
+
+		dir = parent
+	}
+}
+
+// LoadConfig returns the Config read from the giving file path. Commands with relative
+// paths are resolved from the directory of the config file.
+func LoadConfig(path string) (Config, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("%s: invalid plugin config: %s", path, err)
+	}
+
+	config.Dir = filepath.Dir(path)
+
+	for index, plugin := range config.Plugins {
+		if plugin.Name == "" || plugin.Command == "" {
+			return config, fmt.Errorf("%s: plugin %d requires both name and command", path, index)
+		}
+
+		for _, kind := range plugin.Kinds {
+			if !validKind(kind) {
+				return config, fmt.Errorf("%s: plugin %q has unknown kind %q, expected one of %s", path, plugin.Name, kind, strings.Join(Kinds, ", "))
+			}
+		}
+
+		if plugin.Timeout != "" {
+			if _, err := time.ParseDuration(plugin.Timeout); err != nil {
+				return config, fmt.Errorf("%s: plugin %q has invalid timeout: %s", path, plugin.Name, err)
+			}
+		}
+
+		plugin.Dir = config.Dir
+		config.Plugins[index] = plugin
+	}
+
+	return config, nil
+}
+
+// Register adds all plugins of the config into the registry, passing the build tags to
+// each plugin.
+func (c Config) Register(registry *ast.AnnotationRegistry, buildTags []string) {
+	for _, plugin := range c.Plugins {
+		plugin.BuildTags = buildTags
+		plugin.Register(registry)
+	}
+}
+
+// Register adds the plugin as generator into the registry for all it's kinds.
+func (p Plugin) Register(registry *ast.AnnotationRegistry) {
+	kinds := p.Kinds
+	if len(kinds) == 0 {
+		kinds = Kinds
+	}
+
+	for _, kind := range kinds {
+		switch kind {
+		case KindPackage:
+			registry.RegisterPackageContext(p.Name, p.PackageContext)
+		case KindStruct:
+			registry.RegisterStructContext(p.Name, p.StructContext)
+		case KindInterface:
+			registry.RegisterInterfaceContext(p.Name, p.InterfaceContext)
+		case KindType:
+			registry.RegisterTypeContext(p.Name, p.TypeContext)
+		case KindFunction:
+			registry.RegisterFunctionContext(p.Name, p.FunctionContext)
+		case KindVariable:
+			registry.RegisterVariableContext(p.Name, p.VariableContext)
+		}
+	}
+}
+
+// PackageGenerator implements the ast.PackageAnnotationGenerator by running the plugin.
+func (p Plugin) PackageGenerator(toDir string, an ast.AnnotationDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+	return p.Run(p.request(KindPackage, toDir, an, nil, pkgDeclr, pkg))
+}
+
+// StructGenerator implements the ast.StructAnnotationGenerator by running the plugin.
+func (p Plugin) StructGenerator(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+	declr := NewStructDeclaration(str)
+	return p.Run(p.request(KindStruct, toDir, an, &declr, pkgDeclr, pkg))
+}
+
+// InterfaceGenerator implements the ast.InterfaceAnnotationGenerator by running the plugin.
+func (p Plugin) InterfaceGenerator(toDir string, an ast.AnnotationDeclaration, inter ast.InterfaceDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+	declr := NewInterfaceDeclaration(inter)
+	return p.Run(p.request(KindInterface, toDir, an, &declr, pkgDeclr, pkg))
+}
+
+// TypeGenerator implements the ast.TypeAnnotationGenerator by running the plugin.
+func (p Plugin) TypeGenerator(toDir string, an ast.AnnotationDeclaration, typ ast.TypeDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+	declr := NewTypeDeclaration(typ)
+	return p.Run(p.request(KindType, toDir, an, &declr, pkgDeclr, pkg))
+}
+
+// FunctionGenerator implements the ast.FunctionAnnotationGenerator by running the plugin.
+func (p Plugin) FunctionGenerator(toDir string, an ast.AnnotationDeclaration, fn ast.FuncDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+	declr := NewFunctionDeclaration(fn)
+	return p.Run(p.request(KindFunction, toDir, an, &declr, pkgDeclr, pkg))
+}
+
+// VariableGenerator implements the ast.VariableAnnotationGenerator by running the plugin.
+func (p Plugin) VariableGenerator(toDir string, an ast.AnnotationDeclaration, variable ast.VariableDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+	declr := NewVariableDeclaration(variable)
+	return p.Run(p.request(KindVariable, toDir, an, &declr, pkgDeclr, pkg))
+}
+
+// PackageContext implements the ast.PackageContextGenerator by running the plugin, which is
+// stopped when the context of the run is cancelled.
+func (p Plugin) PackageContext(ctx ast.GeneratorContext) ([]gen.WriteDirective, error) {
+	return p.RunContext(ctx.Context, p.request(KindPackage, ctx.ToPath, ctx.Annotation, nil, ctx.Declaration, ctx.Package))
+}
+
+// StructContext implements the ast.StructContextGenerator by running the plugin, which is
+// stopped when the context of the run is cancelled.
+func (p Plugin) StructContext(ctx ast.GeneratorContext, str ast.StructDeclaration) ([]gen.WriteDirective, error) {
+	declr := NewStructDeclaration(str)
+	return p.RunContext(ctx.Context, p.request(KindStruct, ctx.ToPath, ctx.Annotation, &declr, ctx.Declaration, ctx.Package))
+}
+
+// InterfaceContext implements the ast.InterfaceContextGenerator by running the plugin, which is
+// stopped when the context of the run is cancelled.
+func (p Plugin) InterfaceContext(ctx ast.GeneratorContext, inter ast.InterfaceDeclaration) ([]gen.WriteDirective, error) {
+	declr := NewInterfaceDeclaration(inter)
+	return p.RunContext(ctx.Context, p.request(KindInterface, ctx.ToPath, ctx.Annotation, &declr, ctx.Declaration, ctx.Package))
+}
+
+// TypeContext implements the ast.TypeContextGenerator by running the plugin, which is
+// stopped when the context of the run is cancelled.
+func (p Plugin) TypeContext(ctx ast.GeneratorContext, typ ast.TypeDeclaration) ([]gen.WriteDirective, error) {
+	declr := NewTypeDeclaration(typ)
+	return p.RunContext(ctx.Context, p.request(KindType, ctx.ToPath, ctx.Annotation, &declr, ctx.Declaration, ctx.Package))
+}
+
+// FunctionContext implements the ast.FunctionContextGenerator by running the plugin, which is
+// stopped when the context of the run is cancelled.
+func (p Plugin) FunctionContext(ctx ast.GeneratorContext, fn ast.FuncDeclaration) ([]gen.WriteDirective, error) {
+	declr := NewFunctionDeclaration(fn)
+	return p.RunContext(ctx.Context, p.request(KindFunction, ctx.ToPath, ctx.Annotation, &declr, ctx.Declaration, ctx.Package))
+}
+
+// VariableContext implements the ast.VariableContextGenerator by running the plugin, which is
+// stopped when the context of the run is cancelled.
+func (p Plugin) VariableContext(ctx ast.GeneratorContext, variable ast.VariableDeclaration) ([]gen.WriteDirective, error) {
+	declr := NewVariableDeclaration(variable)
+	return p.RunContext(ctx.Context, p.request(KindVariable, ctx.ToPath, ctx.Annotation, &declr, ctx.Declaration, ctx.Package))
+}
+
+func (p Plugin) request(kind string, toDir string, an ast.AnnotationDeclaration, declr *Declaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) Request {
+	return Request{
+		Version:     ProtocolVersion,
+		Kind:        kind,
+		ToDir:       toDir,
+		BuildTags:   p.BuildTags,
+		Annotation:  an,
+		Declaration: declr,
+		File:        NewFile(pkgDeclr),
+		Package:     NewPackage(pkg),
+	}
+}
+
+// Run executes the plugin's command with the request as JSON on it's stdin, returning the
+// directives read from the Response on it's stdout.
+func (p Plugin) Run(req Request) ([]gen.WriteDirective, error) {
+	return p.RunContext(context.Background(), req)
+}
+
+// RunContext executes the plugin as Run does, killing it's command when the context is done.
+func (p Plugin) RunContext(ctx context.Context, req Request) ([]gen.WriteDirective, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if p.Timeout != "" {
+		timeout, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Plugin %q has invalid timeout: %s", p.Name, err)
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	command := p.Command
+	if strings.ContainsRune(command, filepath.Separator) && !filepath.IsAbs(command) && p.Dir != "" {
+		command = filepath.Join(p.Dir, command)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command, p.Args...)
+	cmd.Dir = p.Dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() != 0 {
+			return nil, fmt.Errorf("Plugin %q failed: %s: %s", p.Name, err, strings.TrimSpace(stderr.String()))
+		}
+
+		return nil, fmt.Errorf("Plugin %q failed: %s", p.Name, err)
+	}
+
+	var res Response
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return nil, fmt.Errorf("Plugin %q returned invalid response: %s", p.Name, err)
+	}
+
+	if res.Version != ProtocolVersion {
+		return nil, fmt.Errorf("Plugin %q uses protocol version %d, expected %d", p.Name, res.Version, ProtocolVersion)
+	}
+
+	if res.Error != "" {
+		return nil, fmt.Errorf("Plugin %q: %s", p.Name, res.Error)
+	}
+
+	directives := make([]gen.WriteDirective, 0, len(res.Directives))
+	for _, directive := range res.Directives {
+		target := filepath.Join(directive.Dir, directive.FileName)
+		if filepath.IsAbs(target) || target == ".." || strings.HasPrefix(target, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("Plugin %q returned directive outside of the destination: %q", p.Name, target)
+		}
+
+		wd := gen.WriteDirective{
+			Dir:          directive.Dir,
+			FileName:     directive.FileName,
+			DontOverride: directive.DontOverride,
+		}
+
+		if directive.FileName != "" {
+			wd.Writer = gen.NewConstantWriter([]byte(directive.Content))
+		}
+
+		directives = append(directives, wd)
+	}
+
+	return directives, nil
+}
+
+func validKind(kind string) bool {
+	for _, known := range Kinds {
+		if known == kind {
+			return true
+		}
+	}
+
+	return false
+}
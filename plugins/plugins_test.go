@@ -0,0 +1,147 @@
+package plugins_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/faux/tests"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+	"github.com/influx6/moz/plugins"
+)
+
+const helperEnv = "MOZ_PLUGIN_HELPER"
+
+var pluginSource = `package mock
+
+// Ignitable defines a struct which is used to ignite the package.
+// @ignite(fire)
+type Ignitable struct {
+	Name string
+}
+`
+
+// TestHelperPlugin is not a real test, but the plugin executed by TestPluginRun through
+// the test binary.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(helperEnv) == "" {
+		return
+	}
+
+	registry := ast.NewAnnotationRegistry()
+	registry.RegisterStructType("ignite", func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		return []gen.WriteDirective{
+			{
+				Dir:      "ignite",
+				FileName: "ignite.go",
+				Writer:   gen.Fmt("package ignite\n\n// %s uses %s.\n", str.Name, an.Arguments[0]),
+			},
+		}, nil
+	})
+
+	registry.RegisterStructType("escape", func(toDir string, an ast.AnnotationDeclaration, str ast.StructDeclaration, pkgDeclr ast.PackageDeclaration, pkg ast.Package) ([]gen.WriteDirective, error) {
+		return []gen.WriteDirective{{Dir: "..", FileName: "escaped.go", Writer: gen.Text("package escaped\n")}}, nil
+	})
+
+	plugins.Serve(registry)
+	os.Exit(0)
+}
+
+// TestPluginRun validates the running of a plugin executable through the registry.
+func TestPluginRun(t *testing.T) {
+	config := fmt.Sprintf(`{"plugins": [{"name": "ignite", "command": %q, "args": ["-test.run=TestHelperPlugin"], "kinds": ["struct"], "timeout": "1m"}]}`, os.Args[0])
+	dir, pkgs := loadPackage(t, map[string]string{"mock.go": pluginSource, plugins.ConfigFile: config})
+
+	os.Setenv(helperEnv, "1")
+	defer os.Unsetenv(helperEnv)
+
+	loaded, err := plugins.FindConfig(dir)
+	if err != nil {
+		tests.Failed("Should have found plugin config: %+q", err)
+	}
+	tests.Passed("Should have found plugin config")
+
+	registry := ast.NewAnnotationRegistry()
+	loaded.Register(registry, nil)
+
+	if _, err := registry.GetStructType("ignite"); err != nil {
+		tests.Failed("Should have registered plugin for structs: %+q", err)
+	}
+	tests.Passed("Should have registered plugin for structs")
+
+	if _, err := registry.GetInterfaceType("ignite"); err == nil {
+		tests.Failed("Should have not registered plugin for interfaces")
+	}
+	tests.Passed("Should have not registered plugin for interfaces")
+
+	directives, err := registry.ParseDeclr(pkgs[0], pkgs[0].Packages[0], "mock")
+	if err != nil {
+		tests.Failed("Should have successfully run plugin: %+q", err)
+	}
+	tests.Passed("Should have successfully run plugin")
+
+	if len(directives) != 1 || directives[0].Dir != "ignite" || directives[0].FileName != "ignite.go" {
+		tests.Info("Received: %+v", directives)
+		tests.Failed("Should have received directive from plugin")
+	}
+	tests.Passed("Should have received directive from plugin")
+
+	var content bytes.Buffer
+	if _, err := directives[0].Writer.WriteTo(&content); err != nil {
+		tests.Failed("Should have written directive content: %+q", err)
+	}
+
+	if content.String() != "package ignite\n\n// Ignitable uses fire.\n" {
+		tests.Info("Received: %q", content.String())
+		tests.Failed("Should have received content rendered by plugin")
+	}
+	tests.Passed("Should have received content rendered by plugin")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	str := pkgs[0].Packages[0].Structs[0]
+	genCtx := ast.GeneratorContext{Context: cancelled, Annotation: str.Annotations[0], Declaration: pkgs[0].Packages[0], Package: pkgs[0]}
+	if _, err := loaded.Plugins[0].StructContext(genCtx, str); err == nil {
+		tests.Failed("Should have stopped plugin with cancelled context")
+	}
+	tests.Passed("Should have stopped plugin with cancelled context")
+
+	escape := str.Annotations[0]
+	escape.Name = "@escape"
+
+	genCtx = ast.GeneratorContext{Context: context.Background(), Annotation: escape, Declaration: pkgs[0].Packages[0], Package: pkgs[0]}
+	if _, err := loaded.Plugins[0].StructContext(genCtx, str); err == nil || !strings.Contains(err.Error(), "outside of the destination") {
+		tests.Info("Error: %+v", err)
+		tests.Failed("Should have rejected directive outside of destination")
+	}
+	tests.Passed("Should have rejected directive outside of destination")
+}
+
+// loadPackage writes the files, keyed by their name, into a temporary directory removed once
+// the test ends, returning the directory and it's packages.
+func loadPackage(t *testing.T, files map[string]string) (string, ast.Packages) {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			tests.Failed("Should have written file %q: %+q", name, err)
+		}
+	}
+
+	pkgs, err := ast.FilteredPackageWithBuildCtx(metrics.New(), dir, build.Default)
+	if err != nil {
+		tests.Failed("Should have loaded package: %+q", err)
+	}
+	tests.Passed("Should have loaded package")
+
+	return dir, pkgs
+}
@@ -0,0 +1,225 @@
+// Package plugins provides the protocol for running annotation generators as external
+// executables. The moz command sends a Request as JSON on the plugin's stdin and expects a
+// Response as JSON on it's stdout, which allows generators to be shipped as separate binaries
+// instead of being compiled into a custom moz command.
+package plugins
+
+import (
+	"path/filepath"
+
+	"github.com/influx6/moz/ast"
+)
+
+// ProtocolVersion defines the version of the Request and Response messages.
+const ProtocolVersion = 1
+
+// Contains the declaration kinds a plugin can generate for.
+const (
+	KindPackage   = "package"
+	KindStruct    = "struct"
+	KindInterface = "interface"
+	KindType      = "type"
+	KindFunction  = "function"
+	KindVariable  = "variable"
+)
+
+// Kinds contains all declaration kinds supported by the plugin protocol.
+var Kinds = []string{KindPackage, KindStruct, KindInterface, KindType, KindFunction, KindVariable}
+
+// Request defines the message sent to a plugin on it's stdin for a annotation found on a
+// declaration. Declaration is nil for package level annotations.
+type Request struct {
+	Version     int                       `json:"version"`
+	Kind        string                    `json:"kind"`
+	ToDir       string                    `json:"toDir"`
+	BuildTags   []string                  `json:"buildTags"`
+	Annotation  ast.AnnotationDeclaration `json:"annotation"`
+	Declaration *Declaration              `json:"declaration,omitempty"`
+	File        File                      `json:"file"`
+	Package     Package                   `json:"package"`
+}
+
+// Response defines the message returned by a plugin on it's stdout. A non-empty Error
+// fails the generation of the annotation.
+type Response struct {
+	Version    int         `json:"version"`
+	Error      string      `json:"error,omitempty"`
+	Directives []Directive `json:"directives"`
+}
+
+// Directive defines the serializable form of a gen.WriteDirective, carrying the already
+// rendered content of the file.
+type Directive struct {
+	Dir          string `json:"dir"`
+	FileName     string `json:"fileName"`
+	Content      string `json:"content"`
+	DontOverride bool   `json:"dontOverride"`
+}
+
+// Package defines the serializable form of a ast.Package.
+type Package struct {
+	Name  string   `json:"name"`
+	Tag   string   `json:"tag"`
+	Path  string   `json:"path"`
+	Dir   string   `json:"dir"`
+	Files []string `json:"files"`
+}
+
+// File defines the serializable form of a ast.PackageDeclaration, which holds the declarations
+// of a single file of a package.
+type File struct {
+	Package      string                      `json:"package"`
+	Path         string                      `json:"path"`
+	Dir          string                      `json:"dir"`
+	FilePath     string                      `json:"filePath"`
+	File         string                      `json:"file"`
+	Comments     []string                    `json:"comments"`
+	Imports      []Import                    `json:"imports"`
+	Annotations  []ast.AnnotationDeclaration `json:"annotations"`
+	Declarations []Declaration               `json:"declarations"`
+}
+
+// Import defines the serializable form of a ast.ImportDeclaration.
+type Import struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Internal bool   `json:"internal"`
+}
+
+// Declaration defines the serializable form of a interface, struct, type or function
+// declaration found within a file.
+type Declaration struct {
+	Kind        string                      `json:"kind"`
+	Name        string                      `json:"name"`
+	Receiver    string                      `json:"receiver,omitempty"`
+	From        int                         `json:"from"`
+	Length      int                         `json:"length"`
+	Source      string                      `json:"source"`
+	Comments    string                      `json:"comments"`
+	Annotations []ast.AnnotationDeclaration `json:"annotations"`
+}
+
+// NewPackage returns the serializable form of the ast.Package.
+func NewPackage(pkg ast.Package) Package {
+	return Package{
+		Name:  pkg.Name,
+		Tag:   pkg.Tag,
+		Path:  pkg.Path,
+		Dir:   pkg.Dir,
+		Files: pkg.Files,
+	}
+}
+
+// NewFile returns the serializable form of the ast.PackageDeclaration, including summaries
+// of all declarations found within it.
+func NewFile(declr ast.PackageDeclaration) File {
+	file := File{
+		Package:     declr.Package,
+		Path:        declr.Path,
+		Dir:         declr.Dir,
+		FilePath:    declr.FilePath,
+		File:        declr.File,
+		Comments:    declr.Comments,
+		Annotations: declr.Annotations,
+	}
+
+	if file.File == "" {
+		file.File = filepath.Base(declr.FilePath)
+	}
+
+	for _, imp := range declr.Imports {
+		file.Imports = append(file.Imports, Import{
+			Name:     imp.Name,
+			Path:     imp.Path,
+			Internal: imp.InternalPkg,
+		})
+	}
+
+	for _, item := range declr.Interfaces {
+		file.Declarations = append(file.Declarations, NewInterfaceDeclaration(item))
+	}
+
+	for _, item := range declr.Structs {
+		file.Declarations = append(file.Declarations, NewStructDeclaration(item))
+	}
+
+	for _, item := range declr.Types {
+		file.Declarations = append(file.Declarations, NewTypeDeclaration(item))
+	}
+
+	for _, item := range declr.Functions {
+		file.Declarations = append(file.Declarations, NewFunctionDeclaration(item))
+	}
+
+	for _, item := range declr.Variables {
+		file.Declarations = append(file.Declarations, NewVariableDeclaration(item))
+	}
+
+	return file
+}
+
+// NewInterfaceDeclaration returns the serializable form of the ast.InterfaceDeclaration.
+func NewInterfaceDeclaration(item ast.InterfaceDeclaration) Declaration {
+	return Declaration{
+		Kind:        KindInterface,
+		Name:        item.Name,
+		From:        item.From,
+		Length:      item.Length,
+		Source:      item.Source,
+		Comments:    item.Comments,
+		Annotations: item.Annotations,
+	}
+}
+
+// NewStructDeclaration returns the serializable form of the ast.StructDeclaration.
+func NewStructDeclaration(item ast.StructDeclaration) Declaration {
+	return Declaration{
+		Kind:        KindStruct,
+		Name:        item.Name,
+		From:        item.From,
+		Length:      item.Length,
+		Source:      item.Source,
+		Comments:    item.Comments,
+		Annotations: item.Annotations,
+	}
+}
+
+// NewTypeDeclaration returns the serializable form of the ast.TypeDeclaration.
+func NewTypeDeclaration(item ast.TypeDeclaration) Declaration {
+	return Declaration{
+		Kind:        KindType,
+		Name:        item.Name,
+		From:        item.From,
+		Length:      item.Length,
+		Source:      item.Source,
+		Comments:    item.Comments,
+		Annotations: item.Annotations,
+	}
+}
+
+// NewFunctionDeclaration returns the serializable form of the ast.FuncDeclaration.
+func NewFunctionDeclaration(item ast.FuncDeclaration) Declaration {
+	return Declaration{
+		Kind:        KindFunction,
+		Name:        item.FuncName,
+		Receiver:    item.RecieverName,
+		From:        item.From,
+		Length:      item.Length,
+		Source:      item.Source,
+		Comments:    item.Comments,
+		Annotations: item.Annotations,
+	}
+}
+
+// NewVariableDeclaration returns the serializable form of the ast.VariableDeclaration.
+func NewVariableDeclaration(item ast.VariableDeclaration) Declaration {
+	return Declaration{
+		Kind:        KindVariable,
+		Name:        item.Name,
+		From:        item.From,
+		Length:      item.Length,
+		Source:      item.Source,
+		Comments:    item.Comments,
+		Annotations: item.Annotations,
+	}
+}
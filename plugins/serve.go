@@ -0,0 +1,187 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/influx6/faux/metrics"
+	"github.com/influx6/gobuild/build"
+	"github.com/influx6/moz/ast"
+	"github.com/influx6/moz/gen"
+)
+
+// Serve runs a plugin which reads a Request from stdin and writes the Response of the
+// generator registered for the request's annotation into stdout. It exits with a non-zero
+// status if the generation fails.
+//
+// It allows any existing generator to be shipped as a plugin:
+//
+//	func main() {
+//		registry := ast.NewAnnotationRegistry()
+//		registry.RegisterStructType("mongo", MongoGenerator)
+//		plugins.Serve(registry)
+//	}
+func Serve(registry *ast.AnnotationRegistry) {
+	if err := ServeWith(os.Stdin, os.Stdout, registry); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+// ServeWith reads a Request from r and writes the Response of the generator registered for the
+// request's annotation into w. The package of the request is loaded from disk, so generators
+// receive the same declarations they would receive if compiled into the moz command. The Before
+// and After hooks of returned directives are not run, as they can not cross process boundaries.
+func ServeWith(r io.Reader, w io.Writer, registry *ast.AnnotationRegistry) error {
+	var req Request
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("invalid request: %s", err)
+	}
+
+	var res Response
+	res.Version = ProtocolVersion
+
+	directives, err := Generate(req, registry)
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	for _, directive := range directives {
+		item := Directive{
+			Dir:          directive.Dir,
+			FileName:     directive.FileName,
+			DontOverride: directive.DontOverride,
+		}
+
+		if directive.Writer != nil {
+			var content bytes.Buffer
+			if _, err := directive.Writer.WriteTo(&content); err != nil && err != io.EOF {
+				res.Error = fmt.Sprintf("failed to render %q: %s", directive.FileName, err)
+				break
+			}
+
+			item.Content = content.String()
+		}
+
+		res.Directives = append(res.Directives, item)
+	}
+
+	if res.Error != "" {
+		res.Directives = nil
+	}
+
+	return json.NewEncoder(w).Encode(res)
+}
+
+// Generate loads the package of the request and runs the generator registered for the request's
+// annotation against the declaration of the request.
+func Generate(req Request, registry *ast.AnnotationRegistry) ([]gen.WriteDirective, error) {
+	if req.Version != ProtocolVersion {
+		return nil, fmt.Errorf("request uses protocol version %d, expected %d", req.Version, ProtocolVersion)
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = req.BuildTags
+
+	pkgs, err := ast.FilteredPackageWithBuildCtx(metrics.New(), req.File.Dir, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg ast.Package
+	var pkgDeclr ast.PackageDeclaration
+	var found bool
+
+	for _, item := range pkgs {
+		for _, declr := range append(item.Packages, item.TestPackages...) {
+			if declr.FilePath == req.File.FilePath {
+				pkg, pkgDeclr, found = item, declr, true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("file %q not found in package at %q", req.File.FilePath, req.File.Dir)
+	}
+
+	if req.Kind == KindPackage {
+		generator, err := registry.GetPackage(req.Annotation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		return generator(req.ToDir, req.Annotation, pkgDeclr, pkg)
+	}
+
+	if req.Declaration == nil {
+		return nil, fmt.Errorf("request of kind %q has no declaration", req.Kind)
+	}
+
+	declr := *req.Declaration
+
+	switch req.Kind {
+	case KindStruct:
+		generator, err := registry.GetStructType(req.Annotation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range pkgDeclr.Structs {
+			if item.Name == declr.Name && item.From == declr.From {
+				return generator(req.ToDir, req.Annotation, item, pkgDeclr, pkg)
+			}
+		}
+	case KindInterface:
+		generator, err := registry.GetInterfaceType(req.Annotation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range pkgDeclr.Interfaces {
+			if item.Name == declr.Name && item.From == declr.From {
+				return generator(req.ToDir, req.Annotation, item, pkgDeclr, pkg)
+			}
+		}
+	case KindType:
+		generator, err := registry.GetType(req.Annotation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range pkgDeclr.Types {
+			if item.Name == declr.Name && item.From == declr.From {
+				return generator(req.ToDir, req.Annotation, item, pkgDeclr, pkg)
+			}
+		}
+	case KindFunction:
+		generator, err := registry.GetFunctionType(req.Annotation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range pkgDeclr.Functions {
+			if item.FuncName == declr.Name && item.From == declr.From {
+				return generator(req.ToDir, req.Annotation, item, pkgDeclr, pkg)
+			}
+		}
+	case KindVariable:
+		generator, err := registry.GetVariableType(req.Annotation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range pkgDeclr.Variables {
+			if item.Name == declr.Name && item.From == declr.From {
+				return generator(req.ToDir, req.Annotation, item, pkgDeclr, pkg)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown declaration kind %q", req.Kind)
+	}
+
+	return nil, fmt.Errorf("%s %q not found in %q", req.Kind, declr.Name, req.File.FilePath)
+}